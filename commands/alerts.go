@@ -0,0 +1,247 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/Cmolloy36/gator/internal/match"
+	"github.com/Cmolloy36/gator/internal/output"
+	"github.com/google/uuid"
+)
+
+func HandlerAlert(s *State, cmd Command, user database.User) error {
+	switch cmd.Options.String("action") {
+	case "add":
+		return addAlertRule(s, cmd, user)
+	case "list":
+		return listAlertRules(s, user)
+	case "rm":
+		return removeAlertRule(s, cmd, user)
+	}
+	return nil
+}
+
+func addAlertRule(s *State, cmd Command, user database.User) error {
+	text := cmd.Options.String("pattern")
+	regex := cmd.Options.Bool("regex")
+	if text == "" {
+		return fmt.Errorf("error: \"alert add\" needs a pattern to look for")
+	}
+
+	pattern, err := match.Compile(text, regex)
+	if err != nil {
+		return fmt.Errorf("error: %v", err)
+	}
+
+	var feedID uuid.NullUUID
+	scope := "followed feeds"
+	if ref := cmd.Options.String("feed"); ref != "" {
+		feed, err := resolveFeed(s, ref)
+		if err != nil {
+			return err
+		}
+		feedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+		scope = feed.Name
+	}
+
+	createAlertRuleParams := database.CreateAlertRuleParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Pattern:   text,
+		IsRegex:   regex,
+		FeedID:    feedID,
+		CreatedAt: time.Now(),
+	}
+
+	rule, err := s.Db.CreateAlertRule(context.Background(), createAlertRuleParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in addAlertRule: %v", err)
+	}
+
+	s.Out.Successf("Added alert %s for %s in new posts from %s", shortPostID(rule.ID), pattern, scope)
+
+	return nil
+}
+
+func listAlertRules(s *State, user database.User) error {
+	rules, err := s.Db.GetAlertRulesForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in listAlertRules: %v", err)
+	}
+
+	if s.Out.Structured() {
+		records := make([]alertRuleRecord, len(rules))
+		for i, rule := range rules {
+			records[i] = newAlertRuleRecord(rule)
+		}
+		return s.Out.Encode(records)
+	}
+
+	if len(rules) == 0 {
+		return fmt.Errorf("%s has no alert rules, add one with \"gator alert add <pattern>\"", user.Name)
+	}
+
+	table := output.NewTable("ID", "PATTERN", "FEED", "MATCHES", "CREATED").
+		SetStyle(0, output.Dim)
+	for _, rule := range rules {
+		feed := "all followed"
+		if rule.FeedName.Valid {
+			feed = rule.FeedName.String
+		}
+		table.Row(shortPostID(rule.ID), alertPattern(rule.Pattern, rule.IsRegex), feed, fmt.Sprint(rule.Matches), output.Ago(rule.CreatedAt))
+	}
+	s.Out.Table(table)
+
+	return nil
+}
+
+// alertPattern shows a pattern the way match.Pattern.String does.
+func alertPattern(pattern string, regex bool) string {
+	if regex {
+		return "/" + pattern + "/"
+	}
+	return pattern
+}
+
+func removeAlertRule(s *State, cmd Command, user database.User) error {
+	ref := cmd.Options.String("pattern")
+	if ref == "" {
+		return fmt.Errorf("error: \"alert rm\" needs the id or pattern of a rule (see \"gator alert list\")")
+	}
+
+	rules, err := s.Db.GetAlertRulesForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in removeAlertRule: %v", err)
+	}
+
+	matches := []database.GetAlertRulesForUserRow{}
+	for _, rule := range rules {
+		if rule.Pattern == ref || strings.HasPrefix(rule.ID.String(), strings.ToLower(ref)) {
+			matches = append(matches, rule)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("%s has no alert rule %s", user.Name, ref)
+	case 1:
+	default:
+		return fmt.Errorf("alert rule %s is ambiguous, use its id from \"gator alert list\"", ref)
+	}
+
+	deleteAlertRuleParams := database.DeleteAlertRuleParams{
+		ID:     matches[0].ID,
+		UserID: user.ID,
+	}
+
+	err = s.Db.DeleteAlertRule(context.Background(), deleteAlertRuleParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in removeAlertRule: %v", err)
+	}
+
+	s.Out.Successf("Removed alert %s (%s) and its %d match(es)", shortPostID(matches[0].ID), alertPattern(matches[0].Pattern, matches[0].IsRegex), matches[0].Matches)
+
+	return nil
+}
+
+func HandlerAlerts(s *State, cmd Command, user database.User) error {
+	limit := cmd.Options.Int("limit")
+	if limit < 1 {
+		return fmt.Errorf("error: --limit must be at least 1")
+	}
+
+	var since sql.NullTime
+	if d := cmd.Options.Duration("since"); d > 0 {
+		since = sql.NullTime{Time: time.Now().Add(-d), Valid: true}
+	}
+
+	getAlertsForUserParams := database.GetAlertsForUserParams{
+		UserID:    user.ID,
+		Since:     since,
+		PageLimit: int32(limit),
+	}
+
+	alerts, err := s.Db.GetAlertsForUser(context.Background(), getAlertsForUserParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerAlerts: %v", err)
+	}
+
+	if s.Out.Structured() {
+		records := make([]alertRecord, len(alerts))
+		for i, alert := range alerts {
+			records[i] = newAlertRecord(alert)
+		}
+		return s.Out.Encode(records)
+	}
+
+	if len(alerts) == 0 {
+		s.Out.Println("No alerts")
+		return nil
+	}
+
+	table := output.NewTable("MATCHED", "PATTERN", "FEED", "POST", "URL").
+		SetStyle(4, output.Dim)
+	for _, alert := range alerts {
+		table.Row(
+			output.Ago(alert.CreatedAt),
+			alertPattern(alert.Pattern, alert.IsRegex),
+			alert.FeedName,
+			output.Truncate(postTitle(alert.PostTitle), 50),
+			alert.PostUrl.String,
+		)
+	}
+	s.Out.Table(table)
+
+	return nil
+}
+
+// checkAlerts matches new posts against the alert rules that apply to
+// feed, records each match and prints it.
+func checkAlerts(s *State, feed database.Feed, posts []database.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	rules, err := s.Db.GetAlertRulesForFeed(context.Background(), feed.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		pattern, err := match.Compile(rule.Pattern, rule.IsRegex)
+		if err != nil {
+			s.Out.Warnf("skipping alert %s of %s: %v", shortPostID(rule.ID), rule.UserName, err)
+			continue
+		}
+
+		for _, post := range posts {
+			if !pattern.Match(post.Title.String, output.StripHTML(post.Description.String)) {
+				continue
+			}
+
+			createAlertParams := database.CreateAlertParams{
+				ID:        uuid.New(),
+				RuleID:    rule.ID,
+				PostID:    post.ID,
+				CreatedAt: time.Now(),
+			}
+
+			created, err := s.Db.CreateAlert(context.Background(), createAlertParams)
+			if err != nil {
+				return err
+			}
+			// Already recorded when an earlier scrape was retried
+			if created == 0 {
+				continue
+			}
+
+			s.Out.Printf("%s for %s (%s): %s\n    %s\n", s.Out.Style("Alert", output.Red), rule.UserName, pattern, postTitle(post.Title), post.Url.String)
+		}
+	}
+
+	return nil
+}
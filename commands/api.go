@@ -0,0 +1,214 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/database"
+)
+
+const (
+	apiDefaultPageSize = 20
+	apiMaxPageSize     = 100
+)
+
+// apiServer serves the JSON API under /api/v1/, the Google Reader API and
+// the Fever API. Every request is resolved to a database.User by
+// authenticate (or the Reader and Fever equivalents), the way
+// MiddlewareLoggedIn does for the CLI.
+type apiServer struct {
+	s                  *State
+	authenticate       func(r *http.Request) (database.User, error)
+	authenticateReader func(r *http.Request) (database.User, error)
+	authenticateFever  func(r *http.Request) (database.User, error)
+}
+
+// apiError is returned by authenticate and handlers to choose the status
+// code of an error response.
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e apiError) Error() string {
+	return e.message
+}
+
+func newAPIServer(s *State) *apiServer {
+	return &apiServer{
+		s: s,
+		authenticate: func(r *http.Request) (database.User, error) {
+			return authenticateAPIKey(s, r)
+		},
+		authenticateReader: func(r *http.Request) (database.User, error) {
+			return authenticateReader(s, r)
+		},
+		authenticateFever: func(r *http.Request) (database.User, error) {
+			return authenticateFever(s, r)
+		},
+	}
+}
+
+func HandlerServe(s *State, cmd Command) error {
+	api := newAPIServer(s)
+
+	server := &http.Server{
+		Addr:              cmd.Options.String("addr"),
+		Handler:           api.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	s.Out.Printf("Serving the gator API on %s (Ctrl-C to stop)\n", server.Addr)
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("error: unable to serve on %s: %v", server.Addr, err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerServe: %v", err)
+	}
+
+	s.Out.Println("Server stopped")
+
+	return nil
+}
+
+func (a *apiServer) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/v1/me", a.authed(RoleReadOnly, a.handleMe))
+	mux.HandleFunc("GET /api/v1/users", a.authed(RoleReadOnly, a.handleListUsers))
+
+	mux.HandleFunc("GET /api/v1/feeds", a.authed(RoleReadOnly, a.handleListFeeds))
+	mux.HandleFunc("POST /api/v1/feeds", a.authed(RoleMember, a.handleCreateFeed))
+	mux.HandleFunc("GET /api/v1/feeds/{id}", a.authed(RoleReadOnly, a.handleGetFeed))
+	mux.HandleFunc("DELETE /api/v1/feeds/{id}", a.authed(RoleMember, a.handleDeleteFeed))
+
+	mux.HandleFunc("GET /api/v1/follows", a.authed(RoleReadOnly, a.handleListFollows))
+	mux.HandleFunc("POST /api/v1/follows", a.authed(RoleMember, a.handleCreateFollow))
+	mux.HandleFunc("DELETE /api/v1/follows/{feed_id}", a.authed(RoleMember, a.handleDeleteFollow))
+
+	mux.HandleFunc("GET /api/v1/timeline.atom", a.handleTimelineFeed("atom"))
+	mux.HandleFunc("GET /api/v1/timeline.rss", a.handleTimelineFeed("rss"))
+
+	mux.HandleFunc("GET /api/v1/posts", a.authed(RoleReadOnly, a.handleListPosts))
+	mux.HandleFunc("GET /api/v1/posts/{id}", a.authed(RoleReadOnly, a.handleGetPost))
+	mux.HandleFunc("PUT /api/v1/posts/{id}/read", a.authed(RoleReadOnly, a.handleSetRead(true)))
+	mux.HandleFunc("DELETE /api/v1/posts/{id}/read", a.authed(RoleReadOnly, a.handleSetRead(false)))
+	mux.HandleFunc("PUT /api/v1/posts/{id}/star", a.authed(RoleReadOnly, a.handleSetStarred(true)))
+	mux.HandleFunc("DELETE /api/v1/posts/{id}/star", a.authed(RoleReadOnly, a.handleSetStarred(false)))
+
+	a.readerRoutes(mux)
+
+	mux.HandleFunc("/fever", a.handleFever)
+	mux.HandleFunc("/fever/", a.handleFever)
+
+	return mux
+}
+
+// authed resolves the request's user and checks their role before calling
+// handler, mirroring MiddlewareLoggedIn and MiddlewareRole.
+func (a *apiServer) authed(role string, handler func(w http.ResponseWriter, r *http.Request, user database.User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := a.authenticate(r)
+		if err != nil {
+			var apiErr apiError
+			if errors.As(err, &apiErr) && apiErr.status == http.StatusUnauthorized {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="gator"`)
+			}
+			respondWithErr(w, err)
+			return
+		}
+
+		if !hasRole(user, role) {
+			respondWithError(w, http.StatusForbidden, fmt.Sprintf("requires the %s role, %s is %s", role, user.Name, user.Role))
+			return
+		}
+
+		handler(w, r, user)
+	}
+}
+
+func respondWithJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(payload)
+}
+
+func respondWithError(w http.ResponseWriter, status int, message string) {
+	respondWithJSON(w, status, map[string]string{"error": message})
+}
+
+// respondWithErr writes err with the status of an apiError. Other errors
+// come from the database or the driver, so they are logged and the client
+// only gets a generic internal server error.
+func respondWithErr(w http.ResponseWriter, err error) {
+	var apiErr apiError
+	if errors.As(err, &apiErr) {
+		respondWithError(w, apiErr.status, apiErr.message)
+		return
+	}
+	log.Printf("unexpected error serving the API: %v", err)
+	respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+}
+
+func decodeJSONBody(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return apiError{http.StatusBadRequest, fmt.Sprintf("invalid JSON body: %v", err)}
+	}
+	return nil
+}
+
+// queryInt reads a non-negative integer query parameter, returning def when
+// it is absent.
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, apiError{http.StatusBadRequest, fmt.Sprintf("%s must be a non-negative integer", name)}
+	}
+	return n, nil
+}
+
+func queryBool(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, apiError{http.StatusBadRequest, fmt.Sprintf("%s must be true or false", name)}
+	}
+	return b, nil
+}
@@ -0,0 +1,483 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func pathUUID(r *http.Request, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(r.PathValue(name))
+	if err != nil {
+		return uuid.Nil, apiError{http.StatusBadRequest, fmt.Sprintf("%s must be a UUID", name)}
+	}
+	return id, nil
+}
+
+func (a *apiServer) handleMe(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondWithJSON(w, http.StatusOK, newUserRecord(user, user.Name))
+}
+
+func (a *apiServer) handleListUsers(w http.ResponseWriter, r *http.Request, user database.User) {
+	users, err := a.s.Db.GetUsers(r.Context())
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	records := make([]userRecord, len(users))
+	for i, u := range users {
+		records[i] = newUserRecord(u, user.Name)
+	}
+
+	respondWithJSON(w, http.StatusOK, records)
+}
+
+func (a *apiServer) feedRecord(ctx context.Context, feed database.Feed) (feedRecord, error) {
+	owner, err := a.s.Db.GetFeedUser(ctx, feed.Url)
+	if err != nil {
+		return feedRecord{}, err
+	}
+	return newFeedRecord(feed, owner), nil
+}
+
+func (a *apiServer) handleListFeeds(w http.ResponseWriter, r *http.Request, user database.User) {
+	feeds, err := a.s.Db.GetFeeds(r.Context())
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	records := make([]feedRecord, len(feeds))
+	for i, feed := range feeds {
+		records[i], err = a.feedRecord(r.Context(), feed)
+		if err != nil {
+			respondWithErr(w, err)
+			return
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, records)
+}
+
+// lookupFeed returns the feed named by the {name} path value.
+func (a *apiServer) lookupFeed(r *http.Request, name string) (database.Feed, error) {
+	id, err := pathUUID(r, name)
+	if err != nil {
+		return database.Feed{}, err
+	}
+
+	feed, err := a.s.Db.GetFeedByID(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Feed{}, apiError{http.StatusNotFound, fmt.Sprintf("feed %s does not exist", id)}
+	}
+	return feed, err
+}
+
+func (a *apiServer) handleGetFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	feed, err := a.lookupFeed(r, "id")
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	record, err := a.feedRecord(r.Context(), feed)
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, record)
+}
+
+func (a *apiServer) handleCreateFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	var body struct {
+		Name string `json:"name"`
+		Url  string `json:"url"`
+	}
+	if err := decodeJSONBody(r, &body); err != nil {
+		respondWithErr(w, err)
+		return
+	}
+	if body.Name == "" || body.Url == "" {
+		respondWithError(w, http.StatusBadRequest, "name and url are required")
+		return
+	}
+
+	existing, err := a.s.Db.GetFeed(r.Context(), body.Url)
+	if err == nil {
+		respondWithError(w, http.StatusConflict, fmt.Sprintf("%s is already used by feed %s", body.Url, existing.ID))
+		return
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		respondWithErr(w, err)
+		return
+	}
+
+	createFeedParams := database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		Name:      body.Name,
+		Url:       body.Url,
+		UserID:    user.ID,
+	}
+
+	feed, err := a.s.Db.CreateFeed(r.Context(), createFeedParams)
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	createFeedFollowParams := database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	}
+
+	_, err = a.s.Db.CreateFeedFollow(r.Context(), createFeedFollowParams)
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, newFeedRecord(feed, user.Name))
+}
+
+func (a *apiServer) handleDeleteFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	feed, err := a.lookupFeed(r, "id")
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	if !canManageFeed(user, feed) {
+		respondWithError(w, http.StatusForbidden, "only the user who added the feed or an admin can remove it")
+		return
+	}
+
+	if err := a.s.Db.DeleteFeed(r.Context(), feed.ID); err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *apiServer) handleListFollows(w http.ResponseWriter, r *http.Request, user database.User) {
+	follows, err := a.s.Db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	records := make([]followRecord, len(follows))
+	for i, follow := range follows {
+		records[i] = newFollowRecord(follow)
+	}
+
+	respondWithJSON(w, http.StatusOK, records)
+}
+
+func (a *apiServer) handleCreateFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	var body struct {
+		FeedID  string `json:"feed_id"`
+		FeedUrl string `json:"feed_url"`
+	}
+	if err := decodeJSONBody(r, &body); err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	var feed database.Feed
+	var err error
+	switch {
+	case body.FeedID != "":
+		id, parseErr := uuid.Parse(body.FeedID)
+		if parseErr != nil {
+			respondWithError(w, http.StatusBadRequest, "feed_id must be a UUID")
+			return
+		}
+		feed, err = a.s.Db.GetFeedByID(r.Context(), id)
+	case body.FeedUrl != "":
+		feed, err = a.s.Db.GetFeed(r.Context(), body.FeedUrl)
+	default:
+		respondWithError(w, http.StatusBadRequest, "feed_id or feed_url is required")
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "feed does not exist")
+		return
+	} else if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	follows, err := a.s.Db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+	for _, follow := range follows {
+		if follow.FeedID == feed.ID {
+			respondWithError(w, http.StatusConflict, fmt.Sprintf("%s already follows feed %s", user.Name, feed.ID))
+			return
+		}
+	}
+
+	createFeedFollowParams := database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	}
+
+	follow, err := a.s.Db.CreateFeedFollow(r.Context(), createFeedFollowParams)
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, followRecord{
+		FeedID:     follow.FeedID.String(),
+		FeedName:   follow.FeedName,
+		FeedUrl:    feed.Url,
+		FollowedAt: follow.CreatedAt,
+	})
+}
+
+func (a *apiServer) handleDeleteFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedID, err := pathUUID(r, "feed_id")
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	unfollowFeedParams := database.UnfollowFeedParams{
+		UserID: user.ID,
+		FeedID: feedID,
+	}
+
+	if err := a.s.Db.UnfollowFeed(r.Context(), unfollowFeedParams); err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListPosts lists posts from followed feeds, newest first. Query
+// parameters: limit, offset, feed_id, unread, starred and since (an RFC
+// 3339 time or a duration such as 24h).
+func (a *apiServer) handleListPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	params, err := postsQueryParams(r, user)
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	posts, err := a.s.Db.GetPostsForUserWithState(r.Context(), params)
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	filters, err := loadMuteFilters(r.Context(), a.s, user)
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	// Offsets count hidden posts too, so pages stay stable
+	records := []apiPostRecord{}
+	hidden := 0
+	for _, post := range posts {
+		if filters.hides(post.Title.String, post.Author.String, post.Categories, post.Url.String) {
+			hidden++
+			continue
+		}
+		records = append(records, newAPIPostRecord(database.GetPostWithStateRow(post)))
+	}
+
+	page := struct {
+		Posts      []apiPostRecord `json:"posts"`
+		Limit      int32           `json:"limit"`
+		Offset     int32           `json:"offset"`
+		NextOffset *int32          `json:"next_offset"`
+		Hidden     int             `json:"hidden"`
+	}{
+		Posts:  records,
+		Limit:  params.PageLimit,
+		Offset: params.PageOffset,
+		Hidden: hidden,
+	}
+	if len(posts) == int(params.PageLimit) {
+		next := params.PageOffset + params.PageLimit
+		page.NextOffset = &next
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+func postsQueryParams(r *http.Request, user database.User) (database.GetPostsForUserWithStateParams, error) {
+	params := database.GetPostsForUserWithStateParams{UserID: user.ID}
+	query := r.URL.Query()
+
+	limit, err := queryInt(r, "limit", apiDefaultPageSize)
+	if err != nil {
+		return params, err
+	}
+	if limit < 1 || limit > apiMaxPageSize {
+		return params, apiError{http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", apiMaxPageSize)}
+	}
+
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil {
+		return params, err
+	}
+
+	params.PageLimit = int32(limit)
+	params.PageOffset = int32(offset)
+
+	if value := query.Get("feed_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return params, apiError{http.StatusBadRequest, "feed_id must be a UUID"}
+		}
+		params.FeedID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	if params.UnreadOnly, err = queryBool(r, "unread"); err != nil {
+		return params, err
+	}
+	if params.StarredOnly, err = queryBool(r, "starred"); err != nil {
+		return params, err
+	}
+
+	if value := query.Get("since"); value != "" {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			d, durErr := time.ParseDuration(value)
+			if durErr != nil {
+				return params, apiError{http.StatusBadRequest, "since must be an RFC 3339 time or a duration such as 24h"}
+			}
+			since = time.Now().Add(-d)
+		}
+		params.Since = sql.NullTime{Time: since, Valid: true}
+	}
+
+	return params, nil
+}
+
+// lookupPost returns the post named by the {id} path value. Posts of feeds
+// the user does not follow do not exist for them.
+func (a *apiServer) lookupPost(r *http.Request, user database.User) (database.GetPostWithStateRow, error) {
+	id, err := pathUUID(r, "id")
+	if err != nil {
+		return database.GetPostWithStateRow{}, err
+	}
+
+	getPostWithStateParams := database.GetPostWithStateParams{
+		UserID: user.ID,
+		PostID: id,
+	}
+
+	post, err := a.s.Db.GetPostWithState(r.Context(), getPostWithStateParams)
+	if errors.Is(err, sql.ErrNoRows) {
+		return post, apiError{http.StatusNotFound, fmt.Sprintf("post %s does not exist", id)}
+	}
+	return post, err
+}
+
+// handleGetPost returns a post, unless the user's filters hide it. Hidden
+// posts can still be marked read or starred.
+func (a *apiServer) handleGetPost(w http.ResponseWriter, r *http.Request, user database.User) {
+	post, err := a.lookupPost(r, user)
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	filters, err := loadMuteFilters(r.Context(), a.s, user)
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	if filters.hides(post.Title.String, post.Author.String, post.Categories, post.Url.String) {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("post %s is hidden by your filters (see \"gator filter list\")", post.ID))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, newAPIPostRecord(post))
+}
+
+func (a *apiServer) handleSetRead(read bool) func(http.ResponseWriter, *http.Request, database.User) {
+	return func(w http.ResponseWriter, r *http.Request, user database.User) {
+		post, err := a.lookupPost(r, user)
+		if err != nil {
+			respondWithErr(w, err)
+			return
+		}
+
+		if read {
+			err = a.s.Db.MarkPostRead(r.Context(), database.MarkPostReadParams{
+				UserID:    user.ID,
+				PostID:    post.ID,
+				CreatedAt: time.Now(),
+			})
+		} else {
+			err = a.s.Db.MarkPostUnread(r.Context(), database.MarkPostUnreadParams{
+				UserID:    user.ID,
+				PostID:    post.ID,
+				UpdatedAt: time.Now(),
+			})
+		}
+		if err != nil {
+			respondWithErr(w, err)
+			return
+		}
+
+		a.respondWithPost(w, r, user)
+	}
+}
+
+func (a *apiServer) handleSetStarred(starred bool) func(http.ResponseWriter, *http.Request, database.User) {
+	return func(w http.ResponseWriter, r *http.Request, user database.User) {
+		post, err := a.lookupPost(r, user)
+		if err != nil {
+			respondWithErr(w, err)
+			return
+		}
+
+		setPostStarredParams := database.SetPostStarredParams{
+			UserID:    user.ID,
+			PostID:    post.ID,
+			CreatedAt: time.Now(),
+			Starred:   starred,
+		}
+
+		if err := a.s.Db.SetPostStarred(r.Context(), setPostStarredParams); err != nil {
+			respondWithErr(w, err)
+			return
+		}
+
+		a.respondWithPost(w, r, user)
+	}
+}
+
+// respondWithPost re-reads the post so the response shows its new state.
+func (a *apiServer) respondWithPost(w http.ResponseWriter, r *http.Request, user database.User) {
+	post, err := a.lookupPost(r, user)
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, newAPIPostRecord(post))
+}
@@ -0,0 +1,9 @@
+package commands
+
+import "testing"
+
+// TestRESTAPI runs the request scripts in testdata/api against the JSON
+// API under /api/v1/.
+func TestRESTAPI(t *testing.T) {
+	replayFixtures(t, "api")
+}
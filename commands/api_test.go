@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/config"
+	"github.com/Cmolloy36/gator/internal/output"
+)
+
+var update = flag.Bool("update", false, "rewrite the .golden files in testdata")
+
+// API keys of the fixtures. Requests in testdata use them verbatim, and
+// the T token of testAPIKey is readerActionToken(testAPIKey).
+const (
+	testAPIKey   = "gator_netnewswire-fixture-key"
+	testFeedKey  = "gator_feed-only-fixture-key"
+	testCarolKey = "gator_carol-fixture-key"
+)
+
+// fixtureSeparator separates requests in a .http file and responses in a
+// .golden file.
+const fixtureSeparator = "\n###\n"
+
+// newTestAPIServer returns the API routes backed by a fakeDB holding
+// alice, who follows two feeds, bob, who follows a third, and carol, who
+// follows alice's feeds but hides sponsored posts.
+func newTestAPIServer() http.Handler {
+	db := newFakeDB()
+
+	alice := db.addUser("alice", "correct horse")
+	bob := db.addUser("bob", "")
+	carol := db.addUser("carol", "")
+	db.addAPIKey(alice, testAPIKey, apiKeyScopeFull)
+	db.addAPIKey(alice, testFeedKey, apiKeyScopeFeed)
+	db.addAPIKey(carol, testCarolKey, apiKeyScopeFull)
+
+	goBlog := db.addFeed("The Go Blog", "https://go.dev/blog/feed.atom", alice)
+	bootDev := db.addFeed("Boot.dev Blog", "https://blog.boot.dev/index.xml", alice)
+	hackerNews := db.addFeed("Hacker News", "https://news.ycombinator.com/rss", bob)
+	db.follow(alice, goBlog)
+	db.follow(alice, bootDev)
+	db.follow(bob, hackerNews)
+	db.follow(carol, goBlog)
+	db.follow(carol, bootDev)
+	db.addFilter(carol, "category", "sponsored")
+
+	day := func(n int) time.Time {
+		return fakeEpoch.AddDate(0, 0, n)
+	}
+
+	released := db.addPost(goBlog, "Go 1.26 is released", "https://go.dev/blog/go1.26", day(1), "The Go Team", "release")
+	db.addPost(bootDev, "Learn SQL the hard way", "https://blog.boot.dev/sql", day(2), "Lane Wagner", "SQL", "tutorial")
+	rangeFunc := db.addPost(goBlog, "Range over function types", "https://go.dev/blog/range-functions", day(3), "Ian Lance Taylor", "language")
+	db.addPost(bootDev, "Sponsored: try the bootcamp", "https://blog.boot.dev/sponsored", day(4), "", "sponsored")
+	db.addPost(goBlog, "Testing time (and other asynchronicities)", "https://go.dev/blog/synctest", day(5), "Damien Neil", "testing")
+	db.addPost(hackerNews, "Show HN: gator", "https://news.ycombinator.com/item?id=1", day(3), "")
+
+	db.markRead(alice, released, day(6))
+	db.star(alice, rangeFunc)
+
+	s := &State{
+		Db:           db,
+		ConfigStruct: &config.Config{},
+		Out:          output.New(io.Discard),
+	}
+	return newAPIServer(s).routes()
+}
+
+// replayFixtures sends the requests written out in testdata/<dir>/*.http
+// to a fresh newTestAPIServer and compares the responses with the .golden
+// file of the same name. Run "go test ./commands -update" to
+// rewrite the golden files after an intended change.
+func replayFixtures(t *testing.T, dir string) {
+	paths, err := filepath.Glob(filepath.Join("testdata", dir, "*.http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no fixtures in testdata/%s", dir)
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".http")
+		t.Run(name, func(t *testing.T) {
+			handler := newTestAPIServer()
+
+			responses := []string{}
+			for _, req := range readFixtureRequests(t, path) {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				responses = append(responses, formatFixtureResponse(rec.Result()))
+			}
+			got := strings.Join(responses, fixtureSeparator)
+
+			golden := strings.TrimSuffix(path, ".http") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if got != string(want) {
+				t.Errorf("responses to %s differ from %s\n--- got\n%s\n--- want\n%s", path, golden, got, want)
+			}
+		})
+	}
+}
+
+// readFixtureRequests parses a .http file: raw HTTP/1.1 requests separated
+// by "###" lines. Lines starting with "#" before a request are comments. A
+// body follows the headers after a blank line; its Content-Length is
+// filled in.
+func readFixtureRequests(t *testing.T, path string) []*http.Request {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := []*http.Request{}
+	for _, chunk := range strings.Split(string(data), fixtureSeparator) {
+		lines := strings.Split(strings.TrimSpace(chunk), "\n")
+		for len(lines) > 0 && strings.HasPrefix(lines[0], "#") {
+			lines = lines[1:]
+		}
+
+		head, body, _ := strings.Cut(strings.Join(lines, "\n"), "\n\n")
+		req, err := http.ReadRequest(bufio.NewReader(strings.NewReader(head + "\n\n")))
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+
+		body = strings.TrimSpace(body)
+		req.Body = io.NopCloser(strings.NewReader(body))
+		req.ContentLength = int64(len(body))
+		requests = append(requests, req)
+	}
+
+	return requests
+}
+
+// fixtureHeaders are the response headers recorded in golden files.
+var fixtureHeaders = []string{"Content-Type", "WWW-Authenticate", "X-Reader-Google-Bad-Token"}
+
+// Values that change on every run, replaced in golden files: the time a
+// stream was generated and the tokens of new sessions.
+var (
+	streamUpdated = regexp.MustCompile(`(?m)^  "updated": \d+(,?)$`)
+	sessionToken  = regexp.MustCompile(`(?m)^(SID|Auth)=[A-Za-z0-9_-]{43}$`)
+)
+
+func formatFixtureResponse(res *http.Response) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "HTTP/1.1 %s\n", res.Status)
+	for _, name := range fixtureHeaders {
+		if value := res.Header.Get(name); value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+	}
+	b.WriteString("\n")
+
+	body, _ := io.ReadAll(res.Body)
+	text := strings.TrimSuffix(string(body), "\n")
+	text = streamUpdated.ReplaceAllString(text, `  "updated": "<now>"$1`)
+	text = sessionToken.ReplaceAllString(text, "$1=<session token>")
+	b.WriteString(text)
+
+	return b.String()
+}
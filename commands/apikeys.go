@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/auth"
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/Cmolloy36/gator/internal/output"
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyPrefix = "gator_"
+	// apiKeyShownLen is how much of a key is kept in clear to recognise it.
+	apiKeyShownLen = len(apiKeyPrefix) + 8
+
+	// Full keys work everywhere a user can authenticate. Feed keys only
+	// read the timeline feeds, where they are given as ?key= and so end
+	// up in URLs and reader configs.
+	apiKeyScopeFull = "full"
+	apiKeyScopeFeed = "feed"
+)
+
+func HandlerAPIKey(s *State, cmd Command, user database.User) error {
+	switch cmd.Options.String("action") {
+	case "create":
+		return createAPIKey(s, cmd, user)
+	case "list":
+		return listAPIKeys(s, user)
+	case "revoke":
+		return revokeAPIKey(s, cmd, user)
+	}
+	return nil
+}
+
+func createAPIKey(s *State, cmd Command, user database.User) error {
+	name := cmd.Options.String("name")
+	if name == "" {
+		name = "default"
+	}
+
+	token, _, err := auth.NewToken()
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in createAPIKey: %v", err)
+	}
+	key := apiKeyPrefix + token
+
+	createAPIKeyParams := database.CreateAPIKeyParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    key[:apiKeyShownLen],
+		KeyHash:   auth.HashToken(key),
+		CreatedAt: time.Now(),
+		Scope:     apiKeyScopeFull,
+	}
+
+	if cmd.Options.Bool("feed") {
+		createAPIKeyParams.Scope = apiKeyScopeFeed
+	} else {
+		createAPIKeyParams.FeverKeyHash = sql.NullString{
+			String: auth.HashToken(feverKey(user.ID, key)),
+			Valid:  true,
+		}
+	}
+
+	_, err = s.Db.CreateAPIKey(context.Background(), createAPIKeyParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in createAPIKey: %v", err)
+	}
+
+	if createAPIKeyParams.Scope == apiKeyScopeFeed {
+		s.Out.Successf("Created feed key \"%s\" for %s, it only reads /timeline.atom and /timeline.rss as ?key=", name, user.Name)
+	} else {
+		s.Out.Successf("Created API key \"%s\" for %s", name, user.Name)
+	}
+	s.Out.Println(key)
+	if createAPIKeyParams.Scope == apiKeyScopeFull {
+		s.Out.Printf("Fever clients sign in with %s as the email and this key as the password\n", user.ID)
+	}
+	s.Out.Warnf("Store it now, it cannot be shown again")
+
+	return nil
+}
+
+func listAPIKeys(s *State, user database.User) error {
+	keys, err := s.Db.GetAPIKeysForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in listAPIKeys: %v", err)
+	}
+
+	if s.Out.Structured() {
+		records := make([]apiKeyRecord, len(keys))
+		for i, key := range keys {
+			records[i] = newAPIKeyRecord(key)
+		}
+		return s.Out.Encode(records)
+	}
+
+	if len(keys) == 0 {
+		return fmt.Errorf("%s has no API keys, create one with \"gator apikey create <name>\"", user.Name)
+	}
+
+	table := output.NewTable("NAME", "KEY", "SCOPE", "CREATED", "LAST USED").
+		SetStyle(1, output.Dim)
+	for _, key := range keys {
+		lastUsed := "never"
+		if key.LastUsedAt.Valid {
+			lastUsed = output.Ago(key.LastUsedAt.Time)
+		}
+		table.Row(key.Name, key.Prefix+"…", key.Scope, output.Ago(key.CreatedAt), lastUsed)
+	}
+	s.Out.Table(table)
+
+	return nil
+}
+
+// revokeAPIKey deletes the key whose name or shown prefix matches the
+// "name" argument.
+func revokeAPIKey(s *State, cmd Command, user database.User) error {
+	ref := cmd.Options.String("name")
+	if ref == "" {
+		return fmt.Errorf("error: \"apikey revoke\" needs the name or prefix of a key (see \"gator apikey list\")")
+	}
+
+	keys, err := s.Db.GetAPIKeysForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in revokeAPIKey: %v", err)
+	}
+
+	prefix := strings.TrimSuffix(ref, "…")
+	matches := []database.ApiKey{}
+	for _, key := range keys {
+		if key.Name == ref || (len(prefix) > len(apiKeyPrefix) && strings.HasPrefix(key.Prefix, prefix)) {
+			matches = append(matches, key)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("%s has no API key %s", user.Name, ref)
+	case 1:
+	default:
+		return fmt.Errorf("API key %s is ambiguous, use its prefix from \"gator apikey list\"", ref)
+	}
+
+	deleteAPIKeyParams := database.DeleteAPIKeyParams{
+		ID:     matches[0].ID,
+		UserID: user.ID,
+	}
+
+	err = s.Db.DeleteAPIKey(context.Background(), deleteAPIKeyParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in revokeAPIKey: %v", err)
+	}
+
+	s.Out.Successf("Revoked API key \"%s\" (%s…)", matches[0].Name, matches[0].Prefix)
+
+	return nil
+}
+
+// authenticateAPIKey resolves the user of an "Authorization: Bearer <key>"
+// header.
+func authenticateAPIKey(s *State, r *http.Request) (database.User, error) {
+	scheme, key, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || key == "" {
+		return database.User{}, apiError{http.StatusUnauthorized, "missing \"Authorization: Bearer <api key>\" header"}
+	}
+
+	return userByAPIKey(r.Context(), s, strings.TrimSpace(key), apiKeyScopeFull)
+}
+
+// userByAPIKey resolves the owner of a key with the given scope and records
+// that it was used.
+func userByAPIKey(ctx context.Context, s *State, key, scope string) (database.User, error) {
+	keyHash := auth.HashToken(key)
+
+	getUserByAPIKeyParams := database.GetUserByAPIKeyParams{
+		KeyHash: keyHash,
+		Scope:   scope,
+	}
+
+	user, err := s.Db.GetUserByAPIKey(ctx, getUserByAPIKeyParams)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.User{}, apiError{http.StatusUnauthorized, "invalid API key"}
+		}
+		return database.User{}, err
+	}
+
+	touchAPIKeyParams := database.TouchAPIKeyParams{
+		KeyHash:    keyHash,
+		LastUsedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}
+
+	err = s.Db.TouchAPIKey(ctx, touchAPIKeyParams)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	return user, nil
+}
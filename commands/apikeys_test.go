@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Cmolloy36/gator/internal/config"
+	"github.com/Cmolloy36/gator/internal/output"
+)
+
+// TestAPIKeyAuth runs the request scripts in testdata/apikeys, which
+// authenticate with missing, unknown and feed-only keys.
+func TestAPIKeyAuth(t *testing.T) {
+	replayFixtures(t, "apikeys")
+}
+
+// TestAPIKeyRole checks that a key carries the role of its owner and that
+// using it is recorded.
+func TestAPIKeyRole(t *testing.T) {
+	db := newFakeDB()
+	dave := db.addUser("dave", "")
+	db.setRole(dave, RoleReadOnly)
+	db.addAPIKey(dave, testAPIKey, apiKeyScopeFull)
+
+	s := &State{
+		Db:           db,
+		ConfigStruct: &config.Config{},
+		Out:          output.New(io.Discard),
+	}
+	handler := newAPIServer(s).routes()
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{http.MethodGet, "/api/v1/me", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/feeds", "", http.StatusOK},
+		{http.MethodPost, "/api/v1/feeds", `{"name": "Go", "url": "https://go.dev/blog/feed.atom"}`, http.StatusForbidden},
+		{http.MethodPost, "/api/v1/follows", `{"feed_url": "https://go.dev/blog/feed.atom"}`, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Authorization", "Bearer "+testAPIKey)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status {
+			t.Errorf("%s %s: got status %d, want %d\n%s", tt.method, tt.path, rec.Code, tt.status, rec.Body)
+		}
+	}
+
+	if !db.apiKeys[0].LastUsedAt.Valid {
+		t.Errorf("using the key did not record when it was last used")
+	}
+}
@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/auth"
+	"github.com/Cmolloy36/gator/internal/database"
+	"golang.org/x/term"
+)
+
+// defaultSessionTTL is how long a login lasts unless "login --ttl" says
+// otherwise.
+const defaultSessionTTL = 30 * 24 * time.Hour
+
+// maxUserSessions is how many sessions a user keeps. Reader clients sign
+// in again whenever they lose their token, so older sessions beyond this
+// are dropped along with expired ones when a new one is created.
+const maxUserSessions = 20
+
+// currentUser resolves the logged in user from the session stored in the
+// config. Configs written before sessions existed only hold a user name,
+// which is still trusted for users without a password.
+func currentUser(s *State) (database.User, error) {
+	cfg := s.ConfigStruct
+
+	if cfg.Session_token != "" {
+		getUserBySessionParams := database.GetUserBySessionParams{
+			TokenHash: auth.HashToken(cfg.Session_token),
+			ExpiresAt: time.Now(),
+		}
+
+		user, err := s.Db.GetUserBySession(context.Background(), getUserBySessionParams)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return database.User{}, fmt.Errorf("unexpected error occurred: %v", err)
+			}
+			return database.User{}, fmt.Errorf("your session has expired, run \"gator login %s\"", cfg.Current_user_name)
+		}
+		return user, nil
+	}
+
+	user, err := s.Db.GetUser(context.Background(), cfg.Current_user_name)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return database.User{}, fmt.Errorf("unexpected error occurred: %v", err)
+		}
+		return database.User{}, fmt.Errorf("%s does not exist", cfg.Current_user_name)
+	}
+
+	if user.PasswordHash.Valid {
+		return database.User{}, fmt.Errorf("%s is password protected, run \"gator login %s\"", user.Name, user.Name)
+	}
+
+	return user, nil
+}
+
+// createSession stores a new session for user and returns its token, which
+// is only kept in the database as a hash.
+func createSession(ctx context.Context, s *State, user database.User, ttl time.Duration) (string, time.Time, error) {
+	token, tokenHash, err := auth.NewToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	createSessionParams := database.CreateSessionParams{
+		TokenHash: tokenHash,
+		UserID:    user.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	err = s.Db.CreateSession(ctx, createSessionParams)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	deleteStaleSessionsParams := database.DeleteStaleSessionsParams{
+		UserID: user.ID,
+		Now:    now,
+		Keep:   maxUserSessions,
+	}
+
+	err = s.Db.DeleteStaleSessions(ctx, deleteStaleSessionsParams)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, createSessionParams.ExpiresAt, nil
+}
+
+// startSession creates a session for user and stores its token in the
+// config, logging them in.
+func startSession(s *State, user database.User, ttl time.Duration) error {
+	token, expiresAt, err := createSession(context.Background(), s, user, ttl)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in startSession: %v", err)
+	}
+
+	// Replace rather than accumulate sessions on repeated logins
+	if s.ConfigStruct.Session_token != "" {
+		s.Db.DeleteSession(context.Background(), auth.HashToken(s.ConfigStruct.Session_token))
+	}
+
+	return s.ConfigStruct.SetSession(user.Name, token, expiresAt)
+}
+
+// promptPassword reads a password without echo. When stdin is not a
+// terminal a single line is read instead, so scripts can pipe it in.
+func promptPassword(s *State, prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := s.stdin().ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("error: no password given on stdin")
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	s.Out.Printf("%s", prompt)
+	password, err := term.ReadPassword(fd)
+	s.Out.Println()
+	if err != nil {
+		return "", fmt.Errorf("unexpected error occurred reading password: %v", err)
+	}
+
+	return string(password), nil
+}
+
+// promptNewPassword asks for a new password twice and returns its hash.
+func promptNewPassword(s *State) (string, error) {
+	password, err := promptPassword(s, "New password: ")
+	if err != nil {
+		return "", err
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		again, err := promptPassword(s, "Repeat password: ")
+		if err != nil {
+			return "", err
+		}
+		if again != password {
+			return "", fmt.Errorf("error: passwords do not match")
+		}
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return "", fmt.Errorf("error: %v", err)
+	}
+
+	return hash, nil
+}
+
+func HandlerPasswd(s *State, cmd Command, user database.User) error {
+	if user.PasswordHash.Valid {
+		password, err := promptPassword(s, "Current password: ")
+		if err != nil {
+			return err
+		}
+		if err := auth.CheckPassword(user.PasswordHash.String, password); err != nil {
+			return fmt.Errorf("error: %v", err)
+		}
+	}
+
+	var passwordHash sql.NullString
+	if !cmd.Options.Bool("remove") {
+		hash, err := promptNewPassword(s)
+		if err != nil {
+			return err
+		}
+		passwordHash = sql.NullString{String: hash, Valid: true}
+	}
+
+	setUserPasswordParams := database.SetUserPasswordParams{
+		ID:           user.ID,
+		PasswordHash: passwordHash,
+		UpdatedAt:    time.Now(),
+	}
+
+	err := s.Db.SetUserPassword(context.Background(), setUserPasswordParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerPasswd: %v", err)
+	}
+
+	// Sign out every other session, then continue this one
+	err = s.Db.DeleteSessionsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerPasswd: %v", err)
+	}
+	s.ConfigStruct.Session_token = ""
+
+	err = startSession(s, user, defaultSessionTTL)
+	if err != nil {
+		return err
+	}
+
+	if passwordHash.Valid {
+		s.Out.Successf("Password set for %s", user.Name)
+	} else {
+		s.Out.Successf("Password removed for %s", user.Name)
+	}
+
+	return nil
+}
+
+func HandlerLogout(s *State, cmd Command) error {
+	if s.ConfigStruct.Current_user_name == "" {
+		return fmt.Errorf("no user is logged in")
+	}
+
+	if s.ConfigStruct.Session_token != "" {
+		err := s.Db.DeleteSession(context.Background(), auth.HashToken(s.ConfigStruct.Session_token))
+		if err != nil {
+			return fmt.Errorf("unexpected error occurred in HandlerLogout: %v", err)
+		}
+	}
+
+	name := s.ConfigStruct.Current_user_name
+	err := s.ConfigStruct.Logout()
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerLogout: %v", err)
+	}
+
+	s.Out.Successf("Logged out %s", name)
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// openInBrowser launches rawURL with the user's $BROWSER, falling back to
+// the platform opener (xdg-open on Linux). $BROWSER may hold a
+// colon-separated list of commands, each of which may contain %s as a
+// placeholder for the url. Post URLs come from feeds, so only http and
+// https URLs are opened, never files, other schemes or option-like
+// arguments.
+func openInBrowser(rawURL string) error {
+	if rawURL == "" {
+		return fmt.Errorf("post has no url")
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("refusing to open %q, only http and https links are opened", rawURL)
+	}
+	link := u.String()
+
+	candidates := []string{}
+	if browser := os.Getenv("BROWSER"); browser != "" {
+		candidates = append(candidates, strings.Split(browser, ":")...)
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = append(candidates, "open")
+	case "windows":
+		candidates = append(candidates, "rundll32 url.dll,FileProtocolHandler")
+	default:
+		candidates = append(candidates, "xdg-open")
+	}
+
+	var lastErr error
+	for _, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+
+		args := fields[1:]
+		substituted := false
+		for i, arg := range args {
+			if strings.Contains(arg, "%s") {
+				args[i] = strings.ReplaceAll(arg, "%s", link)
+				substituted = true
+			}
+		}
+		if !substituted {
+			args = append(args, link)
+		}
+
+		browserCmd := exec.Command(fields[0], args...)
+		if err := browserCmd.Start(); err != nil {
+			lastErr = err
+			continue
+		}
+
+		// Reap the child without blocking the caller.
+		go browserCmd.Wait()
+
+		return nil
+	}
+
+	return fmt.Errorf("unable to open browser: %v", lastErr)
+}
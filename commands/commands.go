@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"bufio"
+	"cmp"
 	"context"
 	"database/sql"
 	"encoding/xml"
@@ -9,26 +11,45 @@ import (
 	"html"
 	"io"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/Cmolloy36/gator/internal/auth"
 	"github.com/Cmolloy36/gator/internal/config"
 	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/Cmolloy36/gator/internal/output"
 	"github.com/google/uuid"
 )
 
 type State struct {
-	Db           *database.Queries
+	Db           database.Querier
 	ConfigStruct *config.Config
+	Out          *output.Printer
+	// In reads stdin for the shell, passwords and confirmations. It is
+	// shared so that input buffered by one is not lost to the next, as
+	// with "gator shell < script".
+	In *bufio.Reader
+}
+
+// stdin returns s.In, reading os.Stdin when it was not set.
+func (s *State) stdin() *bufio.Reader {
+	if s.In == nil {
+		s.In = bufio.NewReader(os.Stdin)
+	}
+	return s.In
 }
 
 type Command struct {
-	Name string
-	Args []string
+	Name    string
+	Args    []string
+	Options Options
 }
 
 type Commands struct {
 	FunctionMap map[string]func(*State, Command) error
+	InfoMap     map[string]CommandInfo
 }
 
 type RSSFeed struct {
@@ -41,10 +62,13 @@ type RSSFeed struct {
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	PubDate     string   `xml:"pubDate"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories  []string `xml:"category"`
 }
 
 func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
@@ -107,13 +131,13 @@ func MiddlewareLoggedIn(handler func(s *State, cmd Command, user database.User)
 			return fmt.Errorf("no users have been registered")
 		}
 
-		user, err := s.Db.GetUser(context.Background(), currentUserName)
+		if currentUserName == "" {
+			return fmt.Errorf("no user is logged in, run \"gator login <name>\"")
+		}
+
+		user, err := currentUser(s)
 		if err != nil {
-			if !errors.Is(err, sql.ErrNoRows) {
-				return fmt.Errorf("unexpected error occurred: %v", err)
-			} else {
-				return fmt.Errorf("%s does not exist", currentUserName)
-			}
+			return err
 		}
 
 		return handler(s, cmd, user)
@@ -142,11 +166,18 @@ func scrapeFeeds(s *State) error {
 		return fmt.Errorf("unexpected error occurred in scrapeFeeds: %v", err)
 	}
 
-	rssFeed, err := fetchFeed(context.Background(), feed.Url)
+	fetchStart := time.Now()
+	rssFeed, fetchErr := fetchFeed(context.Background(), feed.Url)
+
+	err = recordFeedFetch(s, feed, fetchStart, rssFeed, fetchErr)
 	if err != nil {
 		return fmt.Errorf("unexpected error occurred in scrapeFeeds: %v", err)
 	}
 
+	if fetchErr != nil {
+		return fmt.Errorf("unexpected error occurred in scrapeFeeds: %v", fetchErr)
+	}
+
 	formats := []string{
 		time.RFC1123Z,         // "Mon, 02 Jan 2006 15:04:05 -0700"
 		time.RFC1123,          // "Mon, 02 Jan 2006 15:04:05 MST"
@@ -155,7 +186,10 @@ func scrapeFeeds(s *State) error {
 		"2006-01-02",          // Just date
 	}
 
-	for i, item := range rssFeed.Channel.Item {
+	s.Out.Printf("%s %s (%d items)\n", s.Out.Style("Fetched", output.Green), feed.Name, len(rssFeed.Channel.Item))
+
+	// Posts that are already stored are skipped by CreatePost
+	for _, item := range rssFeed.Channel.Item {
 		var title sql.NullString
 		title.String = item.Title
 		title.Valid = true
@@ -168,6 +202,17 @@ func scrapeFeeds(s *State) error {
 		description.String = item.Description
 		description.Valid = true
 
+		var author sql.NullString
+		author.String = cmp.Or(item.Creator, item.Author)
+		author.Valid = author.String != ""
+
+		categories := []string{}
+		for _, category := range item.Categories {
+			if category = strings.TrimSpace(category); category != "" {
+				categories = append(categories, category)
+			}
+		}
+
 		var publishedAt time.Time
 
 		for _, format := range formats {
@@ -184,28 +229,101 @@ func scrapeFeeds(s *State) error {
 			Url:         url,
 			Description: description,
 			FeedID:      feed.ID,
+			Author:      author,
+			Categories:  categories,
+			FetchedAt:   time.Now(),
 		}
 
 		s.Db.CreatePost(context.Background(), createPostParams)
 
-		fmt.Printf("Item %d Title: %s\n", i, item.Title)
-		fmt.Printf("Item %d PubDate: %s, PubDate in time: %v\n\n", i, item.PubDate, publishedAt)
+		s.Out.Printf("  • %s %s\n", output.Truncate(item.Title, 70), s.Out.Style(output.Ago(publishedAt), output.Dim))
+	}
+
+	err = processNewPosts(s, feed)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in scrapeFeeds: %v", err)
 	}
 
 	return nil
 }
 
-func (c *Commands) Register(name string, f func(*State, Command) error) {
+// processNewPosts checks the feed's new posts against alert rules and
+// queues their webhook deliveries. Posts stay pending until both have
+// been recorded, so when either fails the posts are retried on the feed's
+// next scrape instead of being skipped as duplicates, and one failing
+// does not stop the other.
+func processNewPosts(s *State, feed database.Feed) error {
+	posts, err := s.Db.GetPendingPostsForFeed(context.Background(), feed.ID)
+	if err != nil {
+		return err
+	}
+	if len(posts) == 0 {
+		return nil
+	}
+
+	alertErr := checkAlerts(s, feed, posts)
+
+	hidden, webhookErr := queueWebhookDeliveries(s, feed, posts)
+	if hidden > 0 {
+		s.Out.Println(s.Out.Style(fmt.Sprintf("  %d webhook delivery(s) skipped, hidden by their owner's filters", hidden), output.Dim))
+	}
+
+	if alertErr != nil || webhookErr != nil {
+		return errors.Join(alertErr, webhookErr)
+	}
+
+	ids := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	markPostsProcessedParams := database.MarkPostsProcessedParams{
+		ProcessedAt: time.Now(),
+		Ids:         ids,
+	}
+
+	return s.Db.MarkPostsProcessed(context.Background(), markPostsProcessedParams)
+}
+
+func (c *Commands) Register(name string, info CommandInfo, f func(*State, Command) error) {
 	c.FunctionMap[name] = f
+	c.InfoMap[name] = info
+}
+
+// RegisterLoggedIn registers a handler that runs as the logged in user.
+func (c *Commands) RegisterLoggedIn(name string, info CommandInfo, f func(*State, Command, database.User) error) {
+	info.LoginRequired = true
+	c.Register(name, info, MiddlewareLoggedIn(f))
 }
 
 func (c *Commands) Run(s *State, cmd Command) error {
 	fcn, ok := c.FunctionMap[cmd.Name]
 	if !ok {
-		return fmt.Errorf("error: \"%s\" is not registered", cmd.Name)
+		return c.unknownCommandError(cmd.Name)
+	}
+
+	info := c.InfoMap[cmd.Name]
+	if info.RawArgs {
+		return fcn(s, cmd)
+	}
+
+	if wantsHelp(cmd.Args) {
+		c.printCommandHelp(s.Out, cmd.Name)
+		return nil
 	}
 
-	err := fcn(s, cmd)
+	options, args, err := parseOptions(cmd.Name, info, cmd.Args)
+	if err != nil {
+		return err
+	}
+	cmd.Args = args
+	cmd.Options = options
+
+	if err := applyOutputOptions(s, options); err != nil {
+		return err
+	}
+
+	err = fcn(s, cmd)
 	if err != nil {
 		return err
 	}
@@ -214,12 +332,8 @@ func (c *Commands) Run(s *State, cmd Command) error {
 }
 
 func HandlerAddFeed(s *State, cmd Command, user database.User) error {
-	if len(cmd.Args) != 2 {
-		return fmt.Errorf("error: \"addfeed\" expects feed name & feed url")
-	}
-
-	feedName := cmd.Args[0]
-	feedURL := cmd.Args[1]
+	feedName := cmd.Options.String("name")
+	feedURL := cmd.Options.String("url")
 
 	createFeedParams := database.CreateFeedParams{
 		ID:        uuid.New(),
@@ -246,64 +360,97 @@ func HandlerAddFeed(s *State, cmd Command, user database.User) error {
 		return fmt.Errorf("unexpected error occurred in addFeed CreateFeedFollow: %v", err)
 	}
 
-	fmt.Printf("%+v", feed)
+	s.Out.Successf("Added feed \"%s\" and followed it as %s", feed.Name, user.Name)
+	s.Out.Fields(
+		"Name", feed.Name,
+		"URL", feed.Url,
+		"ID", feed.ID.String(),
+	)
 
 	return nil
 }
 
 func HandlerAggregator(s *State, cmd Command) error {
-	var time_between_requests time.Duration
-	var err error
+	time_between_requests := cmd.Options.Duration("interval")
+	if time_between_requests <= 0 {
+		return fmt.Errorf("error: \"agg\" interval must be positive")
+	}
 
-	if len(cmd.Args) == 0 {
-		time_between_requests, _ = time.ParseDuration("5s")
-	} else if len(cmd.Args) == 1 {
-		time_between_requests, err = time.ParseDuration(cmd.Args[0])
-		if err != nil {
-			return fmt.Errorf("unexpected error occurred in HandlerAggregator: %v", err)
-		}
-	} else {
-		return fmt.Errorf("error: \"agg\" expects a no arguments or a time argument (1h, 2m, etc.)")
+	schedule, err := newDigestSchedule(s, cmd)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("Collecting feeds every %v\n", time_between_requests)
+	s.Out.Printf("Collecting feeds every %v\n", time_between_requests)
+	if schedule != nil {
+		s.Out.Printf("Sending %s's digest to %s every %v\n", schedule.user.Name, strings.Join(schedule.to, ", "), schedule.every)
+	}
 
 	ticker := time.NewTicker(time_between_requests)
 	for ; ; <-ticker.C {
-		scrapeFeeds(s)
+		if err := scrapeFeeds(s); err != nil {
+			s.Out.Warnf("%v", err)
+		}
+
+		if err := deliverWebhooks(s); err != nil {
+			s.Out.Warnf("%v", err)
+		}
+
+		if schedule != nil {
+			if err := schedule.run(s); err != nil {
+				s.Out.Warnf("%v", err)
+			}
+		}
 	}
 
 }
 
 func HandlerBrowser(s *State, cmd Command, user database.User) error {
-	limit := 2
-	var err error
+	limit := cmd.Options.Int("limit")
+	if limit < 1 {
+		return fmt.Errorf("error: \"browse\" limit must be at least 1")
+	}
 
-	// fmt.Printf("%d\n", len(cmd.Args))
-	// fmt.Printf("%v\n", reflect.TypeOf(cmd.Args[0]).Kind() == reflect.Int)
-	// fmt.Printf("%v\n", reflect.TypeOf(cmd.Args[0]).Kind())
+	posts, hidden, err := getVisiblePosts(s, user, limit)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerBrowser: %v", err)
+	}
 
-	if len(cmd.Args) == 1 {
-		limit, err = strconv.Atoi(cmd.Args[0])
-		if err != nil {
-			return fmt.Errorf("unexpected error occurred when parsing limit arg: %v", err)
+	if s.Out.Structured() {
+		records := make([]postRecord, len(posts))
+		for i, post := range posts {
+			records[i] = newPostRecord(post, i+1)
 		}
-	} else {
-		return fmt.Errorf("error: \"browse\" expects a no arguments or an int limit argument")
+		return s.Out.Encode(records)
 	}
 
-	getPostsForUserParams := database.GetPostsForUserParams{
-		UserID: user.ID,
-		Limit:  int32(limit),
+	if len(posts) == 0 && hidden > 0 {
+		s.Out.Println("No posts to show.")
+		s.Out.Println(s.Out.Style(hiddenNotice(hidden), output.Dim))
+		return nil
 	}
 
-	posts, err := s.Db.GetPostsForUser(context.Background(), getPostsForUserParams)
-	if err != nil {
-		return fmt.Errorf("unexpected error occurred in HandlerBrowser: %v", err)
+	if len(posts) == 0 {
+		s.Out.Println("No posts yet. Follow some feeds and run \"agg\".")
+		return nil
 	}
 
+	table := output.NewTable("#", "ID", "PUBLISHED", "FEED", "TITLE").
+		SetStyle(1, output.Cyan).
+		SetStyle(2, output.Dim)
 	for i, post := range posts {
-		fmt.Printf("Post %d: %+v", i, post)
+		table.Row(
+			strconv.Itoa(i+1),
+			shortPostID(post.ID),
+			output.Ago(post.PublishedAt),
+			output.Truncate(post.FeedName, 20),
+			output.Truncate(postTitle(post.Title), 60),
+		)
+	}
+	s.Out.Table(table)
+
+	if hidden > 0 {
+		s.Out.Println(s.Out.Style(hiddenNotice(hidden), output.Dim))
 	}
 
 	return nil
@@ -311,38 +458,49 @@ func HandlerBrowser(s *State, cmd Command, user database.User) error {
 }
 
 func HandlerFeeds(s *State, cmd Command) error {
-	if len(cmd.Args) != 0 {
-		return fmt.Errorf("error: \"feeds\" does not expect an additional argument")
-	}
-
 	feedsList, err := s.Db.GetFeeds(context.Background())
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
 
+	if s.Out.Structured() {
+		records := make([]feedRecord, len(feedsList))
+		for i, feed := range feedsList {
+			username, err := s.Db.GetFeedUser(context.Background(), feed.Url)
+			if err != nil {
+				return fmt.Errorf("%w", err)
+			}
+			records[i] = newFeedRecord(feed, username)
+		}
+		return s.Out.Encode(records)
+	}
+
 	if len(feedsList) == 0 {
 		return fmt.Errorf("there are no feeds in the database")
 	}
 
+	table := output.NewTable("NAME", "OWNER", "LAST FETCHED", "URL").
+		SetStyle(2, output.Dim)
 	for _, feed := range feedsList {
 		username, err := s.Db.GetFeedUser(context.Background(), feed.Url)
 		if err != nil {
 			return fmt.Errorf("%w", err)
 		}
-		fmt.Printf("Feed Name: %s\n", feed.Name)
-		fmt.Printf("Feed url: %s\n", feed.Url)
-		fmt.Printf("Feed user: %s\n\n", username)
+
+		lastFetched := "never"
+		if feed.LastFetchedAt.Valid {
+			lastFetched = output.Ago(feed.LastFetchedAt.Time)
+		}
+
+		table.Row(output.Truncate(feed.Name, 30), username, lastFetched, feed.Url)
 	}
+	s.Out.Table(table)
 
 	return nil
 }
 
 func HandlerFollow(s *State, cmd Command, user database.User) error {
-	if len(cmd.Args) != 1 {
-		return fmt.Errorf("error: \"follow\" expects a url argument")
-	}
-
-	feedURL := cmd.Args[0]
+	feedURL := cmd.Options.String("url")
 
 	emptyFeed := database.Feed{}
 
@@ -367,16 +525,12 @@ func HandlerFollow(s *State, cmd Command, user database.User) error {
 		return fmt.Errorf("unexpected error occurred: %v", err)
 	}
 
-	fmt.Printf("%s is now following feed \"%s\"\n", user.Name, feed.Name)
+	s.Out.Successf("%s is now following feed \"%s\"", user.Name, feed.Name)
 
 	return nil
 }
 
 func HandlerFollowing(s *State, cmd Command, user database.User) error {
-	if len(cmd.Args) != 0 {
-		return fmt.Errorf("error: \"following\" does not expect any arguments")
-	}
-
 	followedFeedList, err := s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
 	if err != nil {
 		if !errors.Is(err, sql.ErrNoRows) {
@@ -384,28 +538,38 @@ func HandlerFollowing(s *State, cmd Command, user database.User) error {
 		}
 	}
 
+	if s.Out.Structured() {
+		records := make([]followRecord, len(followedFeedList))
+		for i, followedFeed := range followedFeedList {
+			records[i] = newFollowRecord(followedFeed)
+		}
+		return s.Out.Encode(records)
+	}
+
 	if len(followedFeedList) == 0 {
-		fmt.Printf("%s is not following any feeds", user.Name)
+		s.Out.Printf("%s is not following any feeds\n", user.Name)
 		return nil
 	}
 
-	fmt.Printf("%s is following:\n", user.Name)
-
+	table := output.NewTable("FEED", "FOLLOWED", "URL").
+		SetStyle(1, output.Dim)
 	for _, followedFeed := range followedFeedList {
-		fmt.Printf("%s\n", followedFeed.FeedName)
+		table.Row(output.Truncate(followedFeed.FeedName, 30), output.Ago(followedFeed.CreatedAt), followedFeed.FeedUrl)
 	}
+	s.Out.Table(table)
 
 	return nil
 }
 
 func HandlerLogin(s *State, cmd Command) error {
-	if len(cmd.Args) != 1 {
-		return fmt.Errorf("error: \"login\" expects a username argument")
-	}
+	name := cmd.Options.String("name")
 
-	name := cmd.Args[0]
+	ttl := cmd.Options.Duration("ttl")
+	if ttl <= 0 {
+		return fmt.Errorf("error: \"login\" --ttl must be positive")
+	}
 
-	_, err := s.Db.GetUser(context.Background(), name)
+	user, err := s.Db.GetUser(context.Background(), name)
 	if err != nil {
 		if !errors.Is(err, sql.ErrNoRows) {
 			return fmt.Errorf("unexpected error occurred: %v", err)
@@ -414,19 +578,84 @@ func HandlerLogin(s *State, cmd Command) error {
 		}
 	}
 
-	s.ConfigStruct.Current_user_name = name
-	// fmt.Printf("%v", s.ConfigStruct.Current_user_name)
-	fmt.Printf("The user has been set: %s\n", s.ConfigStruct.Current_user_name)
-	s.ConfigStruct.SetUser(s.ConfigStruct.Current_user_name)
+	if user.PasswordHash.Valid {
+		password, err := promptPassword(s, "Password: ")
+		if err != nil {
+			return err
+		}
+		if err := auth.CheckPassword(user.PasswordHash.String, password); err != nil {
+			return fmt.Errorf("error: %v", err)
+		}
+	}
+
+	err = startSession(s, user, ttl)
+	if err != nil {
+		return err
+	}
+
+	s.Out.Successf("The user has been set: %s", s.ConfigStruct.Current_user_name)
 	return nil
 }
 
-func HandlerRegister(s *State, cmd Command) error {
-	if len(cmd.Args) == 0 {
-		return fmt.Errorf("error: \"register\" expects a username argument")
+func HandlerOpen(s *State, cmd Command, user database.User) error {
+	post, err := resolvePost(s, user, cmd.Options.String("post"))
+	if err != nil {
+		return err
+	}
+
+	err = openInBrowser(post.Url.String)
+	if err != nil {
+		return err
+	}
+
+	markPostReadParams := database.MarkPostReadParams{
+		UserID:    user.ID,
+		PostID:    post.ID,
+		CreatedAt: time.Now(),
+	}
+
+	err = s.Db.MarkPostRead(context.Background(), markPostReadParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerOpen: %v", err)
+	}
+
+	s.Out.Successf("Opened %s", post.Url.String)
+
+	return nil
+}
+
+func HandlerRead(s *State, cmd Command, user database.User) error {
+	post, err := resolvePost(s, user, cmd.Options.String("post"))
+	if err != nil {
+		return err
 	}
 
-	name := cmd.Args[0]
+	s.Out.Println(s.Out.Style(postTitle(post.Title), output.Bold))
+	s.Out.Fields(
+		"Published", post.PublishedAt.Format("Mon, 02 Jan 2006 15:04"),
+		"URL", post.Url.String,
+	)
+	s.Out.Println()
+	for _, line := range output.Wrap(output.StripHTML(post.Description.String), 80) {
+		s.Out.Println(line)
+	}
+
+	markPostReadParams := database.MarkPostReadParams{
+		UserID:    user.ID,
+		PostID:    post.ID,
+		CreatedAt: time.Now(),
+	}
+
+	err = s.Db.MarkPostRead(context.Background(), markPostReadParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerRead: %v", err)
+	}
+
+	return nil
+}
+
+func HandlerRegister(s *State, cmd Command) error {
+	name := cmd.Options.String("name")
 
 	emptyUser := database.User{}
 
@@ -439,43 +668,63 @@ func HandlerRegister(s *State, cmd Command) error {
 		return fmt.Errorf("%s already exists", name)
 	}
 
+	// Ask before creating the user so a mistyped password leaves nothing behind
+	var passwordHash sql.NullString
+	if cmd.Options.Bool("password") {
+		hash, err := promptNewPassword(s)
+		if err != nil {
+			return err
+		}
+		passwordHash = sql.NullString{String: hash, Valid: true}
+	}
+
+	// The first user administers the database
+	numRecords, err := s.Db.GetNumRecords(context.Background())
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred: %v", err)
+	}
+
+	role := RoleMember
+	if numRecords == 0 {
+		role = RoleAdmin
+	}
+
 	userParams := database.CreateUserParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
 		Name:      name,
+		Role:      role,
 	}
 
-	_, err = s.Db.CreateUser(context.Background(), userParams)
+	user, err = s.Db.CreateUser(context.Background(), userParams)
 	if err != nil {
 		return fmt.Errorf("unexpected error occurred: %v", err)
 	}
 
-	s.ConfigStruct.Current_user_name = cmd.Args[0]
-	// fmt.Printf("%v", s.ConfigStruct.Current_user_name)
-	fmt.Printf("The user has been registered: %s\n", s.ConfigStruct.Current_user_name)
-	s.ConfigStruct.SetUser(s.ConfigStruct.Current_user_name)
-	return nil
-}
+	if passwordHash.Valid {
+		setUserPasswordParams := database.SetUserPasswordParams{
+			ID:           user.ID,
+			PasswordHash: passwordHash,
+			UpdatedAt:    time.Now(),
+		}
 
-func HandlerReset(s *State, cmd Command) error {
-	err := s.Db.ResetUsers(context.Background())
-	if err != nil {
-		return fmt.Errorf("unexpected error occurred: %v", err)
+		err = s.Db.SetUserPassword(context.Background(), setUserPasswordParams)
+		if err != nil {
+			return fmt.Errorf("unexpected error occurred: %v", err)
+		}
 	}
 
-	s.ConfigStruct.SetUser("")
-
-	fmt.Println("The database has been reset.")
+	err = startSession(s, user, defaultSessionTTL)
+	if err != nil {
+		return err
+	}
 
+	s.Out.Successf("The user has been registered: %s", s.ConfigStruct.Current_user_name)
 	return nil
 }
 
 func HandlerUnfollow(s *State, cmd Command, user database.User) error {
-	if len(cmd.Args) != 1 {
-		return fmt.Errorf("error: \"unfollow\" expects a url argument")
-	}
-
-	feedURL := cmd.Args[0]
+	feedURL := cmd.Options.String("url")
 
 	emptyFeed := database.Feed{}
 
@@ -498,32 +747,39 @@ func HandlerUnfollow(s *State, cmd Command, user database.User) error {
 		return fmt.Errorf("unexpected error occurred: %v", err)
 	}
 
-	fmt.Printf("%s is no longer following feed \"%s\"\n", user.Name, feed.Name)
+	s.Out.Successf("%s is no longer following feed \"%s\"", user.Name, feed.Name)
 
 	return nil
 }
 
 func HandlerUsers(s *State, cmd Command) error {
-	if len(cmd.Args) != 0 {
-		return fmt.Errorf("error: \"users\" does not expect an additional argument")
-	}
-
 	usersList, err := s.Db.GetUsers(context.Background())
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
 
+	if s.Out.Structured() {
+		records := make([]userRecord, len(usersList))
+		for i, user := range usersList {
+			records[i] = newUserRecord(user, s.ConfigStruct.Current_user_name)
+		}
+		return s.Out.Encode(records)
+	}
+
 	if len(usersList) == 0 {
 		return fmt.Errorf("there are no users in the database")
 	}
 
+	table := output.NewTable("", "NAME", "ROLE", "REGISTERED").
+		SetStyle(3, output.Dim)
 	for _, user := range usersList {
-		append := ""
-		if user == s.ConfigStruct.Current_user_name {
-			append = " (current)"
+		if user.Name == s.ConfigStruct.Current_user_name {
+			table.StyledRow(output.Green, "*", user.Name+" (current)", user.Role, output.Ago(user.CreatedAt))
+			continue
 		}
-		fmt.Printf("* %s\n", user+append)
+		table.Row("", user.Name, user.Role, output.Ago(user.CreatedAt))
 	}
+	s.Out.Table(table)
 
 	return nil
 }
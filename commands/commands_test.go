@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/Cmolloy36/gator/internal/config"
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/Cmolloy36/gator/internal/output"
+	"github.com/google/uuid"
+)
+
+// processingDB adds the alert and webhook queries processNewPosts makes to
+// fakeDB, with alerts failing while failAlerts is set.
+type processingDB struct {
+	*fakeDB
+
+	rules      []database.GetAlertRulesForFeedRow
+	webhooks   []database.Webhook
+	alerts     map[[2]uuid.UUID]bool
+	deliveries map[[2]uuid.UUID]bool
+	failAlerts bool
+}
+
+func (db *processingDB) GetPendingPostsForFeed(ctx context.Context, feedID uuid.UUID) ([]database.Post, error) {
+	posts := []database.Post{}
+	for _, post := range db.posts {
+		if post.FeedID == feedID && !post.ProcessedAt.Valid {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+func (db *processingDB) MarkPostsProcessed(ctx context.Context, arg database.MarkPostsProcessedParams) error {
+	for i, post := range db.posts {
+		for _, id := range arg.Ids {
+			if post.ID == id {
+				db.posts[i].ProcessedAt.Time = arg.ProcessedAt
+				db.posts[i].ProcessedAt.Valid = true
+			}
+		}
+	}
+	return nil
+}
+
+func (db *processingDB) GetAlertRulesForFeed(ctx context.Context, feedID uuid.UUID) ([]database.GetAlertRulesForFeedRow, error) {
+	return db.rules, nil
+}
+
+func (db *processingDB) CreateAlert(ctx context.Context, arg database.CreateAlertParams) (int64, error) {
+	if db.failAlerts {
+		return 0, errors.New("connection reset")
+	}
+	key := [2]uuid.UUID{arg.RuleID, arg.PostID}
+	if db.alerts[key] {
+		return 0, nil
+	}
+	db.alerts[key] = true
+	return 1, nil
+}
+
+func (db *processingDB) GetWebhooksForFeed(ctx context.Context, feedID uuid.UUID) ([]database.Webhook, error) {
+	return db.webhooks, nil
+}
+
+func (db *processingDB) CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) error {
+	db.deliveries[[2]uuid.UUID{arg.WebhookID, arg.PostID}] = true
+	return nil
+}
+
+// TestProcessNewPosts checks that a failure to record alerts still queues
+// webhook deliveries, and that the posts stay pending until a later
+// scrape records their alerts.
+func TestProcessNewPosts(t *testing.T) {
+	db := &processingDB{
+		fakeDB:     newFakeDB(),
+		alerts:     map[[2]uuid.UUID]bool{},
+		deliveries: map[[2]uuid.UUID]bool{},
+		failAlerts: true,
+	}
+
+	alice := db.addUser("alice", "")
+	goBlog := db.addFeed("The Go Blog", "https://go.dev/blog/feed.atom", alice)
+	released := db.addPost(goBlog, "Go 1.26 is released", "https://go.dev/blog/go1.26", fakeEpoch, "The Go Team")
+	db.addPost(goBlog, "Range over function types", "https://go.dev/blog/range-functions", fakeEpoch, "Ian Lance Taylor")
+
+	rule := database.GetAlertRulesForFeedRow{ID: uuid.New(), UserID: alice.ID, Pattern: "released", UserName: alice.Name}
+	webhook := database.Webhook{ID: uuid.New(), UserID: alice.ID, Url: "https://hooks.example.com/gator"}
+	db.rules = append(db.rules, rule)
+	db.webhooks = append(db.webhooks, webhook)
+
+	s := &State{
+		Db:           db,
+		ConfigStruct: &config.Config{},
+		Out:          output.New(io.Discard),
+	}
+
+	if err := processNewPosts(s, goBlog); err == nil {
+		t.Fatal("processNewPosts succeeded while alerts were failing")
+	}
+	if len(db.deliveries) != 2 {
+		t.Errorf("queued %d webhook deliveries while alerts were failing, want 2", len(db.deliveries))
+	}
+	if pending, _ := db.GetPendingPostsForFeed(context.Background(), goBlog.ID); len(pending) != 2 {
+		t.Fatalf("%d posts pending after a failure, want 2", len(pending))
+	}
+
+	db.failAlerts = false
+	if err := processNewPosts(s, goBlog); err != nil {
+		t.Fatal(err)
+	}
+	if !db.alerts[[2]uuid.UUID{rule.ID, released.ID}] || len(db.alerts) != 1 {
+		t.Errorf("alerts after the retry: %v, want only %s", db.alerts, released.Title.String)
+	}
+	if len(db.deliveries) != 2 {
+		t.Errorf("%d webhook deliveries after the retry, want 2", len(db.deliveries))
+	}
+	if pending, _ := db.GetPendingPostsForFeed(context.Background(), goBlog.ID); len(pending) != 0 {
+		t.Errorf("%d posts still pending after the retry", len(pending))
+	}
+}
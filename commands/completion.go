@@ -0,0 +1,218 @@
+package commands
+
+import (
+	"context"
+	"strings"
+)
+
+// Completion names the set of values offered when completing an argument
+// or flag value in the shell.
+type Completion int
+
+const (
+	CompleteNone Completion = iota
+	CompleteCommands
+	CompleteFeedURLs
+	CompleteFeedNames
+	CompleteFollowedFeedURLs
+	CompleteUsers
+)
+
+const bashCompletion = `# bash completion for gator
+# Load with: source <(gator completion bash)
+_gator() {
+    local cur words cword
+    if declare -F _init_completion >/dev/null 2>&1; then
+        _init_completion -n =: || return
+    else
+        words=("${COMP_WORDS[@]}")
+        cword=$COMP_CWORD
+        cur="${COMP_WORDS[COMP_CWORD]}"
+    fi
+
+    local IFS=$'\n'
+    COMPREPLY=($(gator __complete "${words[@]:1:cword}" 2>/dev/null))
+
+    if declare -F __ltrim_colon_completions >/dev/null 2>&1; then
+        __ltrim_colon_completions "$cur"
+    fi
+}
+complete -o default -F _gator gator
+`
+
+const zshCompletion = `#compdef gator
+# zsh completion for gator
+# Load with: source <(gator completion zsh), or save as _gator in your $fpath
+_gator() {
+    local -a candidates
+    candidates=("${(@f)$(gator __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    candidates=(${candidates:#})
+    (( ${#candidates} )) && compadd -Q -- "${candidates[@]}"
+}
+
+if [ "$funcstack[1]" = "_gator" ]; then
+    _gator "$@"
+else
+    compdef _gator gator
+fi
+`
+
+const fishCompletion = `# fish completion for gator
+# Load with: gator completion fish | source
+function __gator_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    gator __complete $tokens[2..-1] 2>/dev/null
+end
+
+complete -c gator -f -a '(__gator_complete)'
+`
+
+func HandlerCompletion(s *State, cmd Command) error {
+	switch cmd.Options.String("shell") {
+	case "bash":
+		s.Out.Printf("%s", bashCompletion)
+	case "zsh":
+		s.Out.Printf("%s", zshCompletion)
+	case "fish":
+		s.Out.Printf("%s", fishCompletion)
+	}
+
+	return nil
+}
+
+// HandlerComplete backs the shell scripts printed by "completion". It
+// receives the words typed after "gator", the last being the partial word
+// under the cursor, and prints matching candidates one per line. Errors
+// are swallowed so a broken config never disturbs the shell.
+func (c *Commands) HandlerComplete(s *State, cmd Command) error {
+	words := cmd.Args
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	current := words[len(words)-1]
+	for _, candidate := range c.completionCandidates(s, words) {
+		if strings.HasPrefix(candidate, current) {
+			s.Out.Println(candidate)
+		}
+	}
+
+	return nil
+}
+
+func (c *Commands) completionCandidates(s *State, words []string) []string {
+	current := words[len(words)-1]
+	typed := words[:len(words)-1]
+
+	// Skip output flags given before the command name
+	for len(typed) > 0 && isGlobalFlag(typed[0]) {
+		if strings.Contains(typed[0], "=") || len(typed) == 1 {
+			typed = typed[1:]
+			continue
+		}
+		typed = typed[2:]
+	}
+
+	if len(typed) == 0 {
+		if strings.HasPrefix(current, "-") {
+			return []string{"--output", "--format"}
+		}
+		if len(words) > 1 && isGlobalFlag(words[len(words)-2]) && !strings.Contains(words[len(words)-2], "=") {
+			return OutputFlags[0].Values
+		}
+		return c.names()
+	}
+
+	info, ok := c.InfoMap[typed[0]]
+	if !ok || info.Hidden {
+		return nil
+	}
+
+	positional := 0
+	for i := 1; i < len(typed); i++ {
+		token := typed[i]
+		if token == "--" || !strings.HasPrefix(token, "-") || isNegativeNumber(token) {
+			positional++
+			continue
+		}
+
+		name, _, hasValue := strings.Cut(strings.TrimLeft(token, "-"), "=")
+		flag, ok := info.lookupFlag(name)
+		if !ok || hasValue || flag.Kind == BoolOption {
+			continue
+		}
+
+		if i == len(typed)-1 {
+			return c.completeValues(s, flag.Values, flag.Complete)
+		}
+		i++
+	}
+
+	if strings.HasPrefix(current, "-") {
+		candidates := []string{"--help"}
+		for _, flag := range info.Flags {
+			candidates = append(candidates, "--"+flag.Name)
+		}
+		return candidates
+	}
+
+	if positional < len(info.Args) {
+		arg := info.Args[positional]
+		return c.completeValues(s, arg.Values, arg.Complete)
+	}
+
+	return nil
+}
+
+func (c *Commands) completeValues(s *State, values []string, completion Completion) []string {
+	if len(values) > 0 {
+		return values
+	}
+
+	ctx := context.Background()
+	candidates := []string{}
+
+	switch completion {
+	case CompleteCommands:
+		return c.names()
+	case CompleteFeedURLs, CompleteFeedNames:
+		feeds, err := s.Db.GetFeeds(ctx)
+		if err != nil {
+			return nil
+		}
+		for _, feed := range feeds {
+			if completion == CompleteFeedNames {
+				candidates = append(candidates, feed.Name)
+			} else {
+				candidates = append(candidates, feed.Url)
+			}
+		}
+	case CompleteFollowedFeedURLs:
+		user, err := s.Db.GetUser(ctx, s.ConfigStruct.Current_user_name)
+		if err != nil {
+			return nil
+		}
+		follows, err := s.Db.GetFeedFollowsForUser(ctx, user.ID)
+		if err != nil {
+			return nil
+		}
+		for _, follow := range follows {
+			candidates = append(candidates, follow.FeedUrl)
+		}
+	case CompleteUsers:
+		users, err := s.Db.GetUsers(ctx)
+		if err != nil {
+			return nil
+		}
+		for _, user := range users {
+			candidates = append(candidates, user.Name)
+		}
+	}
+
+	return candidates
+}
+
+func isGlobalFlag(token string) bool {
+	name, _, _ := strings.Cut(token, "=")
+	return name == "--output" || name == "-o" || name == "--format"
+}
@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// confirm asks the user a yes/no question on stdin, defaulting to no. It
+// refuses to guess when stdin is not a terminal so scripts must pass --yes.
+func confirm(s *State, question string) (bool, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, fmt.Errorf("error: refusing to continue without confirmation, pass --yes to run non-interactively")
+	}
+
+	s.Out.Printf("%s [y/N] ", question)
+
+	answer, err := s.stdin().ReadString('\n')
+	if err != nil {
+		s.Out.Println()
+		return false, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
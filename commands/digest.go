@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/Cmolloy36/gator/internal/digest"
+	"github.com/Cmolloy36/gator/internal/output"
+)
+
+const (
+	digestMaxPosts    = 500
+	digestSummaryLen  = 200
+	defaultDigestFrom = "gator <gator@localhost>"
+	// digestRetryDelay is how long agg waits to retry a digest it could
+	// not send.
+	digestRetryDelay = 10 * time.Minute
+)
+
+func HandlerDigest(s *State, cmd Command, user database.User) error {
+	since := cmd.Options.Duration("since")
+	if since <= 0 {
+		return fmt.Errorf("error: \"digest\" --since must be positive")
+	}
+
+	to := splitAddresses(cmd.Options.String("to"))
+	printOnly := cmd.Options.Bool("print")
+	if len(to) == 0 && !printOnly {
+		return fmt.Errorf("error: \"digest\" needs --to, or --print to output the message instead")
+	}
+
+	d, err := buildDigest(s, user, fetchWindow{since: time.Now().Add(-since)}, to)
+	if err != nil {
+		return err
+	}
+
+	if printOnly {
+		msg, err := d.Message()
+		if err != nil {
+			return fmt.Errorf("unexpected error occurred in HandlerDigest: %v", err)
+		}
+		_, err = s.Out.Writer().Write(msg)
+		return err
+	}
+
+	if d.Total() == 0 {
+		s.Out.Printf("No new posts since %s, no digest sent\n", d.Since.Format(time.DateTime))
+		return nil
+	}
+
+	if err := sendDigest(s, d); err != nil {
+		return err
+	}
+
+	s.Out.Successf("Sent a digest of %d post(s) to %s", d.Total(), strings.Join(to, ", "))
+
+	return nil
+}
+
+func splitAddresses(list string) []string {
+	addresses := []string{}
+	for _, address := range strings.Split(list, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// buildDigest collects the posts agg stored within window from the feeds
+// user follows, grouped by feed. Posts are picked by when they were
+// fetched, so late and undated posts are not missed. Beyond digestMaxPosts
+// only the newest are listed, and the digest says so.
+func buildDigest(s *State, user database.User, window fetchWindow, to []string) (digest.Digest, error) {
+	posts, hidden, err := getTimelinePosts(context.Background(), s, user, timelineFilter{}, window, digestMaxPosts+1)
+	if err != nil {
+		return digest.Digest{}, fmt.Errorf("unexpected error occurred in buildDigest: %v", err)
+	}
+
+	truncated := len(posts) > digestMaxPosts
+	if truncated {
+		posts = posts[:digestMaxPosts]
+	}
+
+	from := defaultDigestFrom
+	if s.ConfigStruct.Smtp != nil && s.ConfigStruct.Smtp.From != "" {
+		from = s.ConfigStruct.Smtp.From
+	}
+
+	d := digest.Digest{
+		From:      from,
+		To:        to,
+		Subject:   fmt.Sprintf("gator digest: %d new post(s)", len(posts)),
+		Since:     window.since,
+		Date:      time.Now(),
+		Hidden:    hidden,
+		Truncated: truncated,
+	}
+	if truncated {
+		d.Subject = fmt.Sprintf("gator digest: more than %d new posts", digestMaxPosts)
+	}
+
+	groups := map[string]int{}
+	for _, post := range posts {
+		i, ok := groups[post.FeedUrl]
+		if !ok {
+			i = len(d.Groups)
+			groups[post.FeedUrl] = i
+			d.Groups = append(d.Groups, digest.Group{Feed: post.FeedName})
+		}
+
+		d.Groups[i].Posts = append(d.Groups[i].Posts, digest.Post{
+			Title:       postTitle(post.Title),
+			Url:         post.Url.String,
+			Author:      post.Author.String,
+			Summary:     output.Truncate(output.StripHTML(post.Description.String), digestSummaryLen),
+			PublishedAt: post.PublishedAt,
+		})
+	}
+
+	slices.SortFunc(d.Groups, func(a, b digest.Group) int {
+		return strings.Compare(strings.ToLower(a.Feed), strings.ToLower(b.Feed))
+	})
+
+	return d, nil
+}
+
+func sendDigest(s *State, d digest.Digest) error {
+	cfg := s.ConfigStruct.Smtp
+	if cfg == nil || cfg.Host == "" {
+		return fmt.Errorf("error: no SMTP server configured, add \"smtp\" to ~/.gatorconfig.json (see the README)")
+	}
+
+	msg, err := d.Message()
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in sendDigest: %v", err)
+	}
+
+	server := digest.Server{
+		Host:     cfg.Host,
+		Port:     cfg.Port,
+		Username: cfg.Username,
+		Password: cfg.Password,
+	}
+
+	if err := digest.Send(server, d.From, d.To, msg); err != nil {
+		return fmt.Errorf("error: unable to send digest: %v", err)
+	}
+
+	return nil
+}
+
+// digestSchedule sends the logged in user a digest from agg every so
+// often, covering the posts fetched since the previous one. The time of
+// the previous digest is kept in the database, so posts fetched while agg
+// was stopped go into the next one.
+type digestSchedule struct {
+	user    database.User
+	to      []string
+	every   time.Duration
+	retryAt time.Time
+}
+
+func newDigestSchedule(s *State, cmd Command) (*digestSchedule, error) {
+	to := splitAddresses(cmd.Options.String("digest-to"))
+	if len(to) == 0 {
+		return nil, nil
+	}
+
+	every := cmd.Options.Duration("digest-every")
+	if every <= 0 {
+		return nil, fmt.Errorf("error: \"agg\" --digest-every must be positive")
+	}
+
+	if s.ConfigStruct.Smtp == nil || s.ConfigStruct.Smtp.Host == "" {
+		return nil, fmt.Errorf("error: no SMTP server configured, add \"smtp\" to ~/.gatorconfig.json (see the README)")
+	}
+
+	user, err := currentUser(s)
+	if err != nil {
+		return nil, err
+	}
+
+	// The first digest covers the posts fetched from now on
+	_, err = s.Db.GetDigestLastSent(context.Background(), user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = setDigestLastSent(s, user, time.Now())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error occurred in newDigestSchedule: %v", err)
+	}
+
+	return &digestSchedule{user: user, to: to, every: every}, nil
+}
+
+// run sends a digest when one is due. Empty digests are skipped. A digest
+// that could not be sent is retried after digestRetryDelay, still covering
+// every post since the last one sent.
+func (d *digestSchedule) run(s *State) error {
+	now := time.Now()
+	if now.Before(d.retryAt) {
+		return nil
+	}
+
+	last, err := s.Db.GetDigestLastSent(context.Background(), d.user.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in digestSchedule.run: %v", err)
+	}
+	if now.Sub(last) < d.every {
+		return nil
+	}
+
+	// last_sent_at has a time zone, but posts.fetched_at holds agg's local
+	// time without one, so the window is given in local time
+	dg, err := buildDigest(s, d.user, fetchWindow{since: last.Local(), until: now}, d.to)
+	if err != nil {
+		return err
+	}
+
+	if dg.Total() > 0 {
+		if err := sendDigest(s, dg); err != nil {
+			d.retryAt = now.Add(digestRetryDelay)
+			return err
+		}
+
+		s.Out.Printf("%s a digest of %d post(s) to %s\n", s.Out.Style("Sent", output.Green), dg.Total(), strings.Join(d.to, ", "))
+	}
+
+	if err := setDigestLastSent(s, d.user, now); err != nil {
+		return fmt.Errorf("unexpected error occurred in digestSchedule.run: %v", err)
+	}
+
+	return nil
+}
+
+func setDigestLastSent(s *State, user database.User, at time.Time) error {
+	setDigestLastSentParams := database.SetDigestLastSentParams{
+		UserID:     user.ID,
+		LastSentAt: at,
+	}
+
+	return s.Db.SetDigestLastSent(context.Background(), setDigestLastSentParams)
+}
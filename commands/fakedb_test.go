@@ -0,0 +1,665 @@
+package commands
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/auth"
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// fakeDB is an in-memory database.Querier for handler tests. It implements
+// the queries the tested handlers make and panics on any other, through the
+// nil embedded interface. Rows written by handlers get ids and times derived
+// from their content instead of the random ids and current times they were
+// given, so responses stay the same from run to run.
+type fakeDB struct {
+	database.Querier
+
+	users    []database.User
+	apiKeys  []database.ApiKey
+	sessions []database.CreateSessionParams
+	feeds    []database.Feed
+	follows  []database.FeedFollow
+	posts    []database.Post
+	filters  []database.Filter
+	states   map[fakePostState]fakeState
+}
+
+type fakePostState struct {
+	userID uuid.UUID
+	postID uuid.UUID
+}
+
+type fakeState struct {
+	readAt  sql.NullTime
+	starred bool
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{states: map[fakePostState]fakeState{}}
+}
+
+func (db *fakeDB) addUser(name, password string) database.User {
+	user := database.User{
+		ID:        uuid.NewSHA1(uuid.NameSpaceURL, []byte("user:"+name)),
+		CreatedAt: fakeEpoch,
+		UpdatedAt: fakeEpoch,
+		Name:      name,
+		Role:      RoleMember,
+	}
+	if password != "" {
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			panic(err)
+		}
+		user.PasswordHash = sql.NullString{String: hash, Valid: true}
+	}
+	db.users = append(db.users, user)
+	return user
+}
+
+// addAPIKey adds key, which also works as a Fever key unless it is a feed
+// key, as with "apikey create".
+func (db *fakeDB) addAPIKey(user database.User, key, scope string) {
+	apiKey := database.ApiKey{
+		ID:        uuid.NewSHA1(uuid.NameSpaceURL, []byte("key:"+key)),
+		UserID:    user.ID,
+		Name:      scope,
+		Prefix:    key[:apiKeyShownLen],
+		KeyHash:   auth.HashToken(key),
+		CreatedAt: fakeEpoch,
+		Scope:     scope,
+	}
+	if scope == apiKeyScopeFull {
+		apiKey.FeverKeyHash = sql.NullString{String: auth.HashToken(feverKey(user.ID, key)), Valid: true}
+	}
+	db.apiKeys = append(db.apiKeys, apiKey)
+}
+
+func (db *fakeDB) addFeed(name, url string, owner database.User) database.Feed {
+	feed := database.Feed{
+		ID:        uuid.NewSHA1(uuid.NameSpaceURL, []byte(url)),
+		CreatedAt: fakeEpoch,
+		UpdatedAt: fakeEpoch,
+		Name:      name,
+		Url:       url,
+		UserID:    owner.ID,
+		SeqID:     int64(len(db.feeds) + 1),
+	}
+	db.feeds = append(db.feeds, feed)
+	return feed
+}
+
+func (db *fakeDB) follow(user database.User, feed database.Feed) {
+	db.follows = append(db.follows, database.FeedFollow{
+		ID:        uuid.NewSHA1(uuid.NameSpaceURL, []byte(user.Name+"/"+feed.Url)),
+		CreatedAt: fakeEpoch,
+		UpdatedAt: fakeEpoch,
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	})
+}
+
+func (db *fakeDB) addPost(feed database.Feed, title, url string, publishedAt time.Time, author string, categories ...string) database.Post {
+	post := database.Post{
+		ID:          uuid.NewSHA1(uuid.NameSpaceURL, []byte(url)),
+		CreatedAt:   publishedAt,
+		UpdatedAt:   publishedAt,
+		Title:       sql.NullString{String: title, Valid: true},
+		Url:         sql.NullString{String: url, Valid: true},
+		Description: sql.NullString{String: "<p>" + title + "</p>", Valid: true},
+		PublishedAt: publishedAt,
+		FeedID:      feed.ID,
+		SeqID:       int64(len(db.posts) + 1),
+		Author:      sql.NullString{String: author, Valid: author != ""},
+		Categories:  append([]string{}, categories...),
+		FetchedAt:   publishedAt.Add(time.Hour),
+	}
+	db.posts = append(db.posts, post)
+	return post
+}
+
+func (db *fakeDB) setRole(user database.User, role string) {
+	for i := range db.users {
+		if db.users[i].ID == user.ID {
+			db.users[i].Role = role
+		}
+	}
+}
+
+func (db *fakeDB) addFilter(user database.User, field, pattern string) {
+	db.filters = append(db.filters, database.Filter{
+		ID:        uuid.NewSHA1(uuid.NameSpaceURL, []byte(user.Name+"/filter:"+pattern)),
+		UserID:    user.ID,
+		Field:     field,
+		Pattern:   pattern,
+		CreatedAt: fakeEpoch,
+	})
+}
+
+// fakeEpoch is the creation time of every fixture row, and fakeNow the
+// time of every change handlers make.
+var (
+	fakeEpoch = time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+	fakeNow   = fakeEpoch.AddDate(0, 0, 10)
+)
+
+func (db *fakeDB) user(id uuid.UUID) (database.User, error) {
+	for _, user := range db.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (db *fakeDB) feed(id uuid.UUID) database.Feed {
+	for _, feed := range db.feeds {
+		if feed.ID == id {
+			return feed
+		}
+	}
+	panic("fakeDB: no feed " + id.String())
+}
+
+func (db *fakeDB) followsFeed(userID, feedID uuid.UUID) bool {
+	return slices.ContainsFunc(db.follows, func(follow database.FeedFollow) bool {
+		return follow.UserID == userID && follow.FeedID == feedID
+	})
+}
+
+// followedPosts returns the posts of the feeds userID follows, in the
+// order they were added.
+func (db *fakeDB) followedPosts(userID uuid.UUID) []database.Post {
+	posts := []database.Post{}
+	for _, post := range db.posts {
+		if db.followsFeed(userID, post.FeedID) {
+			posts = append(posts, post)
+		}
+	}
+	return posts
+}
+
+func (db *fakeDB) GetUser(ctx context.Context, name string) (database.User, error) {
+	for _, user := range db.users {
+		if user.Name == name {
+			return user, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (db *fakeDB) GetUsers(ctx context.Context) ([]database.User, error) {
+	users := slices.Clone(db.users)
+	slices.SortFunc(users, func(a, b database.User) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return users, nil
+}
+
+func (db *fakeDB) GetUserByAPIKey(ctx context.Context, arg database.GetUserByAPIKeyParams) (database.User, error) {
+	for _, key := range db.apiKeys {
+		if key.KeyHash == arg.KeyHash && key.Scope == arg.Scope {
+			return db.user(key.UserID)
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (db *fakeDB) TouchAPIKey(ctx context.Context, arg database.TouchAPIKeyParams) error {
+	for i, key := range db.apiKeys {
+		if key.KeyHash == arg.KeyHash {
+			db.apiKeys[i].LastUsedAt = arg.LastUsedAt
+		}
+	}
+	return nil
+}
+
+func (db *fakeDB) GetUserByFeverKey(ctx context.Context, feverKeyHash sql.NullString) (database.User, error) {
+	for _, key := range db.apiKeys {
+		if key.FeverKeyHash.Valid && key.FeverKeyHash == feverKeyHash {
+			return db.user(key.UserID)
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (db *fakeDB) TouchFeverKey(ctx context.Context, arg database.TouchFeverKeyParams) error {
+	for i, key := range db.apiKeys {
+		if key.FeverKeyHash == arg.FeverKeyHash {
+			db.apiKeys[i].LastUsedAt = arg.LastUsedAt
+		}
+	}
+	return nil
+}
+
+func (db *fakeDB) CreateSession(ctx context.Context, arg database.CreateSessionParams) error {
+	db.sessions = append(db.sessions, arg)
+	return nil
+}
+
+func (db *fakeDB) DeleteStaleSessions(ctx context.Context, arg database.DeleteStaleSessionsParams) error {
+	kept := []database.CreateSessionParams{}
+	recent := 0
+	for _, session := range slices.Backward(db.sessions) {
+		if !session.ExpiresAt.After(arg.Now) {
+			continue
+		}
+		if session.UserID == arg.UserID {
+			if recent == int(arg.Keep) {
+				continue
+			}
+			recent++
+		}
+		kept = append(kept, session)
+	}
+	slices.Reverse(kept)
+	db.sessions = kept
+	return nil
+}
+
+func (db *fakeDB) GetUserBySession(ctx context.Context, arg database.GetUserBySessionParams) (database.User, error) {
+	for _, session := range db.sessions {
+		if session.TokenHash == arg.TokenHash && session.ExpiresAt.After(arg.ExpiresAt) {
+			return db.user(session.UserID)
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (db *fakeDB) GetFeed(ctx context.Context, url string) (database.Feed, error) {
+	for _, feed := range db.feeds {
+		if feed.Url == url {
+			return feed, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (db *fakeDB) GetFeeds(ctx context.Context) ([]database.Feed, error) {
+	return slices.Clone(db.feeds), nil
+}
+
+func (db *fakeDB) GetFeedByID(ctx context.Context, id uuid.UUID) (database.Feed, error) {
+	for _, feed := range db.feeds {
+		if feed.ID == id {
+			return feed, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (db *fakeDB) GetFeedBySeqID(ctx context.Context, seqID int64) (database.Feed, error) {
+	for _, feed := range db.feeds {
+		if feed.SeqID == seqID {
+			return feed, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (db *fakeDB) GetFollowedFeeds(ctx context.Context, userID uuid.UUID) ([]database.Feed, error) {
+	feeds := []database.Feed{}
+	for _, feed := range db.feeds {
+		if db.followsFeed(userID, feed.ID) {
+			feeds = append(feeds, feed)
+		}
+	}
+	return feeds, nil
+}
+
+func (db *fakeDB) GetFeedUser(ctx context.Context, url string) (string, error) {
+	feed, err := db.GetFeed(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	user, err := db.user(feed.UserID)
+	return user.Name, err
+}
+
+func (db *fakeDB) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	owner, err := db.user(arg.UserID)
+	if err != nil {
+		return database.Feed{}, err
+	}
+	feed := db.addFeed(arg.Name, arg.Url, owner)
+	db.feeds[len(db.feeds)-1].CreatedAt = fakeNow
+	feed.CreatedAt = fakeNow
+	return feed, nil
+}
+
+func (db *fakeDB) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	db.feeds = slices.DeleteFunc(db.feeds, func(feed database.Feed) bool { return feed.ID == id })
+	db.follows = slices.DeleteFunc(db.follows, func(follow database.FeedFollow) bool { return follow.FeedID == id })
+	db.posts = slices.DeleteFunc(db.posts, func(post database.Post) bool { return post.FeedID == id })
+	return nil
+}
+
+func (db *fakeDB) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	user, err := db.user(arg.UserID)
+	if err != nil {
+		return database.CreateFeedFollowRow{}, err
+	}
+	feed := db.feed(arg.FeedID)
+	db.follow(user, feed)
+
+	follow := db.follows[len(db.follows)-1]
+	follow.CreatedAt = fakeNow
+	follow.UpdatedAt = fakeNow
+	db.follows[len(db.follows)-1] = follow
+
+	return database.CreateFeedFollowRow{
+		ID:        follow.ID,
+		CreatedAt: follow.CreatedAt,
+		UpdatedAt: follow.UpdatedAt,
+		UserID:    follow.UserID,
+		FeedID:    follow.FeedID,
+		FeedName:  feed.Name,
+		UserName:  user.Name,
+	}, nil
+}
+
+func (db *fakeDB) UnfollowFeed(ctx context.Context, arg database.UnfollowFeedParams) error {
+	db.follows = slices.DeleteFunc(db.follows, func(follow database.FeedFollow) bool {
+		return follow.UserID == arg.UserID && follow.FeedID == arg.FeedID
+	})
+	return nil
+}
+
+func (db *fakeDB) GetFiltersForUser(ctx context.Context, userID uuid.UUID) ([]database.Filter, error) {
+	filters := []database.Filter{}
+	for _, filter := range db.filters {
+		if filter.UserID == userID {
+			filters = append(filters, filter)
+		}
+	}
+	return filters, nil
+}
+
+func (db *fakeDB) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFeedFollowsForUserRow, error) {
+	user, err := db.user(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []database.GetFeedFollowsForUserRow{}
+	for _, follow := range db.follows {
+		if follow.UserID != userID {
+			continue
+		}
+		feed := db.feed(follow.FeedID)
+		rows = append(rows, database.GetFeedFollowsForUserRow{
+			ID:        follow.ID,
+			CreatedAt: follow.CreatedAt,
+			UpdatedAt: follow.UpdatedAt,
+			UserID:    follow.UserID,
+			FeedID:    follow.FeedID,
+			FeedName:  feed.Name,
+			FeedUrl:   feed.Url,
+			UserName:  user.Name,
+		})
+	}
+	return rows, nil
+}
+
+func (db *fakeDB) readerRow(userID uuid.UUID, post database.Post) database.GetPostsForReaderStreamRow {
+	feed := db.feed(post.FeedID)
+	state := db.states[fakePostState{userID, post.ID}]
+	return database.GetPostsForReaderStreamRow{
+		ID:          post.ID,
+		CreatedAt:   post.CreatedAt,
+		UpdatedAt:   post.UpdatedAt,
+		Title:       post.Title,
+		Url:         post.Url,
+		Description: post.Description,
+		PublishedAt: post.PublishedAt,
+		FeedID:      post.FeedID,
+		SeqID:       post.SeqID,
+		Author:      post.Author,
+		Categories:  post.Categories,
+		FetchedAt:   post.FetchedAt,
+		FeedName:    feed.Name,
+		FeedUrl:     feed.Url,
+		ReadAt:      state.readAt,
+		Starred:     state.starred,
+	}
+}
+
+// byPublishedDesc is the default order of the Reader queries: newest
+// first, ties by sequence id.
+func byPublishedDesc(a, b database.GetPostsForReaderStreamRow) int {
+	if c := b.PublishedAt.Compare(a.PublishedAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.SeqID, b.SeqID)
+}
+
+func (db *fakeDB) GetPostsForReaderStream(ctx context.Context, arg database.GetPostsForReaderStreamParams) ([]database.GetPostsForReaderStreamRow, error) {
+	rows := []database.GetPostsForReaderStreamRow{}
+	for _, post := range db.followedPosts(arg.UserID) {
+		row := db.readerRow(arg.UserID, post)
+		switch {
+		case arg.FeedID.Valid && row.FeedID != arg.FeedID.UUID:
+		case arg.UnreadOnly && row.ReadAt.Valid:
+		case arg.StarredOnly && !row.Starred:
+		case arg.Since.Valid && row.PublishedAt.Before(arg.Since.Time):
+		case arg.Until.Valid && !row.PublishedAt.Before(arg.Until.Time):
+		default:
+			rows = append(rows, row)
+		}
+	}
+
+	slices.SortFunc(rows, func(a, b database.GetPostsForReaderStreamRow) int {
+		if arg.OldestFirst {
+			if c := a.PublishedAt.Compare(b.PublishedAt); c != 0 {
+				return c
+			}
+		}
+		return byPublishedDesc(a, b)
+	})
+
+	start := min(int(arg.PageOffset), len(rows))
+	end := min(start+int(arg.PageLimit), len(rows))
+	return rows[start:end], nil
+}
+
+func (db *fakeDB) GetReaderItems(ctx context.Context, arg database.GetReaderItemsParams) ([]database.GetReaderItemsRow, error) {
+	rows := []database.GetPostsForReaderStreamRow{}
+	for _, post := range db.followedPosts(arg.UserID) {
+		if slices.Contains(arg.SeqIds, post.SeqID) {
+			rows = append(rows, db.readerRow(arg.UserID, post))
+		}
+	}
+	slices.SortFunc(rows, byPublishedDesc)
+
+	items := make([]database.GetReaderItemsRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetReaderItemsRow(row)
+	}
+	return items, nil
+}
+
+func (db *fakeDB) GetFeverItems(ctx context.Context, arg database.GetFeverItemsParams) ([]database.GetFeverItemsRow, error) {
+	rows := []database.GetFeverItemsRow{}
+	for _, post := range db.followedPosts(arg.UserID) {
+		switch {
+		case arg.SinceID.Valid && post.SeqID <= arg.SinceID.Int64:
+		case arg.MaxID.Valid && post.SeqID >= arg.MaxID.Int64:
+		case arg.WithIds != nil && !slices.Contains(arg.WithIds, post.SeqID):
+		default:
+			state := db.states[fakePostState{arg.UserID, post.ID}]
+			rows = append(rows, database.GetFeverItemsRow{
+				ID:          post.ID,
+				CreatedAt:   post.CreatedAt,
+				UpdatedAt:   post.UpdatedAt,
+				Title:       post.Title,
+				Url:         post.Url,
+				Description: post.Description,
+				PublishedAt: post.PublishedAt,
+				FeedID:      post.FeedID,
+				SeqID:       post.SeqID,
+				Author:      post.Author,
+				Categories:  post.Categories,
+				FetchedAt:   post.FetchedAt,
+				FeedSeqID:   db.feed(post.FeedID).SeqID,
+				ReadAt:      state.readAt,
+				Starred:     state.starred,
+			})
+		}
+	}
+
+	if arg.MaxID.Valid {
+		slices.Reverse(rows)
+	}
+	return rows[:min(int(arg.PageLimit), len(rows))], nil
+}
+
+func (db *fakeDB) CountFollowedPosts(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return int64(len(db.followedPosts(userID))), nil
+}
+
+func (db *fakeDB) GetUnreadPostSeqIDs(ctx context.Context, userID uuid.UUID) ([]database.GetUnreadPostSeqIDsRow, error) {
+	rows := []database.GetUnreadPostSeqIDsRow{}
+	for _, post := range db.followedPosts(userID) {
+		if !db.states[fakePostState{userID, post.ID}].readAt.Valid {
+			rows = append(rows, database.GetUnreadPostSeqIDsRow{
+				SeqID:      post.SeqID,
+				Title:      post.Title,
+				Url:        post.Url,
+				Author:     post.Author,
+				Categories: post.Categories,
+			})
+		}
+	}
+	return rows, nil
+}
+
+func (db *fakeDB) GetStarredPostSeqIDs(ctx context.Context, userID uuid.UUID) ([]database.GetStarredPostSeqIDsRow, error) {
+	rows := []database.GetStarredPostSeqIDsRow{}
+	for _, post := range db.posts {
+		if db.states[fakePostState{userID, post.ID}].starred {
+			rows = append(rows, database.GetStarredPostSeqIDsRow{
+				SeqID:      post.SeqID,
+				Title:      post.Title,
+				Url:        post.Url,
+				Author:     post.Author,
+				Categories: post.Categories,
+			})
+		}
+	}
+	return rows, nil
+}
+
+func (db *fakeDB) GetPostsForUserWithState(ctx context.Context, arg database.GetPostsForUserWithStateParams) ([]database.GetPostsForUserWithStateRow, error) {
+	streamParams := database.GetPostsForReaderStreamParams{
+		UserID:      arg.UserID,
+		FeedID:      arg.FeedID,
+		UnreadOnly:  arg.UnreadOnly,
+		StarredOnly: arg.StarredOnly,
+		Since:       arg.Since,
+		PageLimit:   arg.PageLimit,
+		PageOffset:  arg.PageOffset,
+	}
+
+	rows, err := db.GetPostsForReaderStream(ctx, streamParams)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]database.GetPostsForUserWithStateRow, len(rows))
+	for i, row := range rows {
+		posts[i] = database.GetPostsForUserWithStateRow(db.postWithState(row))
+	}
+	return posts, nil
+}
+
+func (db *fakeDB) GetPostWithState(ctx context.Context, arg database.GetPostWithStateParams) (database.GetPostWithStateRow, error) {
+	for _, post := range db.followedPosts(arg.UserID) {
+		if post.ID == arg.PostID {
+			return db.postWithState(db.readerRow(arg.UserID, post)), nil
+		}
+	}
+	return database.GetPostWithStateRow{}, sql.ErrNoRows
+}
+
+func (db *fakeDB) postWithState(row database.GetPostsForReaderStreamRow) database.GetPostWithStateRow {
+	return database.GetPostWithStateRow{
+		ID:          row.ID,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+		Title:       row.Title,
+		Url:         row.Url,
+		Description: row.Description,
+		PublishedAt: row.PublishedAt,
+		FeedID:      row.FeedID,
+		SeqID:       row.SeqID,
+		Author:      row.Author,
+		Categories:  row.Categories,
+		FetchedAt:   row.FetchedAt,
+		FeedName:    row.FeedName,
+		ReadAt:      row.ReadAt,
+		Starred:     row.Starred,
+	}
+}
+
+func (db *fakeDB) GetFollowedPostBySeqID(ctx context.Context, arg database.GetFollowedPostBySeqIDParams) (database.Post, error) {
+	for _, post := range db.followedPosts(arg.UserID) {
+		if post.SeqID == arg.SeqID {
+			return post, nil
+		}
+	}
+	return database.Post{}, sql.ErrNoRows
+}
+
+func (db *fakeDB) MarkPostRead(ctx context.Context, arg database.MarkPostReadParams) error {
+	key := fakePostState{arg.UserID, arg.PostID}
+	state := db.states[key]
+	if !state.readAt.Valid {
+		state.readAt = sql.NullTime{Time: fakeNow, Valid: true}
+	}
+	db.states[key] = state
+	return nil
+}
+
+func (db *fakeDB) MarkPostUnread(ctx context.Context, arg database.MarkPostUnreadParams) error {
+	key := fakePostState{arg.UserID, arg.PostID}
+	state := db.states[key]
+	state.readAt = sql.NullTime{}
+	db.states[key] = state
+	return nil
+}
+
+func (db *fakeDB) SetPostStarred(ctx context.Context, arg database.SetPostStarredParams) error {
+	key := fakePostState{arg.UserID, arg.PostID}
+	state := db.states[key]
+	state.starred = arg.Starred
+	db.states[key] = state
+	return nil
+}
+
+func (db *fakeDB) MarkPostsReadBefore(ctx context.Context, arg database.MarkPostsReadBeforeParams) error {
+	for _, post := range db.followedPosts(arg.UserID) {
+		if arg.FeedID.Valid && post.FeedID != arg.FeedID.UUID || post.CreatedAt.After(arg.Before) {
+			continue
+		}
+		db.MarkPostRead(ctx, database.MarkPostReadParams{UserID: arg.UserID, PostID: post.ID})
+	}
+	return nil
+}
+
+// markRead and star set up post states for fixtures.
+func (db *fakeDB) markRead(user database.User, post database.Post, at time.Time) {
+	key := fakePostState{user.ID, post.ID}
+	state := db.states[key]
+	state.readAt = sql.NullTime{Time: at, Valid: true}
+	db.states[key] = state
+}
+
+func (db *fakeDB) star(user database.User, post database.Post) {
+	db.SetPostStarred(context.Background(), database.SetPostStarredParams{UserID: user.ID, PostID: post.ID, Starred: true})
+}
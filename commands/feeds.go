@@ -0,0 +1,345 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/Cmolloy36/gator/internal/output"
+	"github.com/google/uuid"
+)
+
+// resolveFeed looks up a feed by its URL or, failing that, by its name.
+func resolveFeed(s *State, ref string) (database.Feed, error) {
+	feed, err := s.Db.GetFeed(context.Background(), ref)
+	if err == nil {
+		return feed, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return database.Feed{}, fmt.Errorf("unexpected error occurred in resolveFeed: %v", err)
+	}
+
+	feeds, err := s.Db.GetFeedsByName(context.Background(), ref)
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("unexpected error occurred in resolveFeed: %v", err)
+	}
+
+	switch len(feeds) {
+	case 0:
+		return database.Feed{}, fmt.Errorf("feed %s does not exist", ref)
+	case 1:
+		return feeds[0], nil
+	default:
+		return database.Feed{}, fmt.Errorf("feed name \"%s\" is ambiguous, use the feed's URL", ref)
+	}
+}
+
+// canManageFeed reports whether user may modify or remove feed: its owner
+// or an admin.
+func canManageFeed(user database.User, feed database.Feed) bool {
+	return feed.UserID == user.ID || hasRole(user, RoleAdmin)
+}
+
+func checkFeedOwner(user database.User, feed database.Feed, action string) error {
+	if !canManageFeed(user, feed) {
+		return fmt.Errorf("error: only the user who added feed \"%s\" or an admin can %s it", feed.Name, action)
+	}
+	return nil
+}
+
+func HandlerRmFeed(s *State, cmd Command, user database.User) error {
+	feed, err := resolveFeed(s, cmd.Options.String("feed"))
+	if err != nil {
+		return err
+	}
+
+	if err := checkFeedOwner(user, feed, "remove"); err != nil {
+		return err
+	}
+
+	numPosts, err := s.Db.CountPostsForFeed(context.Background(), feed.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerRmFeed: %v", err)
+	}
+
+	numFollows, err := s.Db.CountFeedFollowsForFeed(context.Background(), feed.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerRmFeed: %v", err)
+	}
+
+	s.Out.Printf("Removing feed \"%s\" (%s) also deletes %d post(s) and %d follow(s).\n", feed.Name, feed.Url, numPosts, numFollows)
+
+	if !cmd.Options.Bool("yes") {
+		ok, err := confirm(s, "Remove this feed?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			s.Out.Warnf("Aborted, feed \"%s\" was not removed", feed.Name)
+			return nil
+		}
+	}
+
+	err = s.Db.DeleteFeed(context.Background(), feed.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerRmFeed: %v", err)
+	}
+
+	s.Out.Successf("Removed feed \"%s\"", feed.Name)
+
+	return nil
+}
+
+func HandlerRenameFeed(s *State, cmd Command, user database.User) error {
+	feed, err := resolveFeed(s, cmd.Options.String("feed"))
+	if err != nil {
+		return err
+	}
+
+	if err := checkFeedOwner(user, feed, "rename"); err != nil {
+		return err
+	}
+
+	renameFeedParams := database.RenameFeedParams{
+		ID:        feed.ID,
+		Name:      cmd.Options.String("name"),
+		UpdatedAt: time.Now(),
+	}
+
+	renamed, err := s.Db.RenameFeed(context.Background(), renameFeedParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerRenameFeed: %v", err)
+	}
+
+	s.Out.Successf("Renamed feed \"%s\" to \"%s\"", feed.Name, renamed.Name)
+
+	return nil
+}
+
+func HandlerSetFeedURL(s *State, cmd Command, user database.User) error {
+	feed, err := resolveFeed(s, cmd.Options.String("feed"))
+	if err != nil {
+		return err
+	}
+
+	if err := checkFeedOwner(user, feed, "edit"); err != nil {
+		return err
+	}
+
+	newURL := cmd.Options.String("url")
+	if newURL == feed.Url {
+		return fmt.Errorf("feed \"%s\" already uses %s", feed.Name, newURL)
+	}
+
+	existing, err := s.Db.GetFeed(context.Background(), newURL)
+	if err == nil {
+		return fmt.Errorf("error: %s is already used by feed \"%s\"", newURL, existing.Name)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("unexpected error occurred in HandlerSetFeedURL: %v", err)
+	}
+
+	purge := cmd.Options.Bool("purge-posts")
+	if purge && !cmd.Options.Bool("yes") {
+		numPosts, err := s.Db.CountPostsForFeed(context.Background(), feed.ID)
+		if err != nil {
+			return fmt.Errorf("unexpected error occurred in HandlerSetFeedURL: %v", err)
+		}
+
+		ok, err := confirm(s, fmt.Sprintf("Delete the %d post(s) fetched from %s?", numPosts, feed.Url))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			s.Out.Warnf("Aborted, feed \"%s\" was not changed", feed.Name)
+			return nil
+		}
+	}
+
+	setFeedURLParams := database.SetFeedURLParams{
+		ID:        feed.ID,
+		Url:       newURL,
+		UpdatedAt: time.Now(),
+	}
+
+	_, err = s.Db.SetFeedURL(context.Background(), setFeedURLParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerSetFeedURL: %v", err)
+	}
+
+	s.Out.Successf("Feed \"%s\" now fetches from %s", feed.Name, newURL)
+
+	if purge {
+		deleted, err := s.Db.DeletePostsForFeed(context.Background(), feed.ID)
+		if err != nil {
+			return fmt.Errorf("unexpected error occurred in HandlerSetFeedURL: %v", err)
+		}
+		s.Out.Printf("Deleted %d post(s) from the old URL\n", deleted)
+	} else {
+		s.Out.Println("Existing posts and follows are kept; the feed is fetched again on the next agg run")
+	}
+
+	return nil
+}
+
+// feedRateWindow is the period over which feedinfo averages posts per week.
+const feedRateWindow = 4 * 7 * 24 * time.Hour
+
+// feedFetchHistory is the number of fetches kept per feed, over which
+// feedinfo reports failures and latency.
+const feedFetchHistory = 100
+
+// recordFeedFetch stores the outcome and latency of a fetch that started at
+// start, for feedinfo, and drops fetches older than the last
+// feedFetchHistory.
+func recordFeedFetch(s *State, feed database.Feed, start time.Time, rssFeed *RSSFeed, fetchErr error) error {
+	createFeedFetchParams := database.CreateFeedFetchParams{
+		ID:         uuid.New(),
+		FeedID:     feed.ID,
+		FetchedAt:  start,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+
+	if fetchErr != nil {
+		createFeedFetchParams.Error = sql.NullString{String: fetchErr.Error(), Valid: true}
+	} else {
+		createFeedFetchParams.ItemCount = int32(len(rssFeed.Channel.Item))
+	}
+
+	err := s.Db.CreateFeedFetch(context.Background(), createFeedFetchParams)
+	if err != nil {
+		return err
+	}
+
+	deleteOldFeedFetchesParams := database.DeleteOldFeedFetchesParams{
+		FeedID: feed.ID,
+		Limit:  feedFetchHistory,
+	}
+
+	return s.Db.DeleteOldFeedFetches(context.Background(), deleteOldFeedFetchesParams)
+}
+
+func HandlerFeedInfo(s *State, cmd Command) error {
+	ctx := context.Background()
+
+	feed, err := resolveFeed(s, cmd.Options.String("feed"))
+	if err != nil {
+		return err
+	}
+
+	owner, err := s.Db.GetFeedUser(ctx, feed.Url)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerFeedInfo: %v", err)
+	}
+
+	followers, err := s.Db.CountFeedFollowsForFeed(ctx, feed.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerFeedInfo: %v", err)
+	}
+
+	record := feedInfoRecord{
+		ID:        feed.ID.String(),
+		Name:      feed.Name,
+		Url:       feed.Url,
+		Owner:     owner,
+		Followers: followers,
+	}
+	if feed.LastFetchedAt.Valid {
+		record.LastFetchedAt = &feed.LastFetchedAt.Time
+	}
+
+	record.Posts, err = s.Db.CountPostsForFeed(ctx, feed.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerFeedInfo: %v", err)
+	}
+
+	// MIN and MAX are NULL for a feed without posts
+	if record.Posts > 0 {
+		getFeedPostStatsParams := database.GetFeedPostStatsParams{
+			Since:  time.Now().Add(-feedRateWindow),
+			FeedID: feed.ID,
+		}
+
+		postStats, err := s.Db.GetFeedPostStats(ctx, getFeedPostStatsParams)
+		if err != nil {
+			return fmt.Errorf("unexpected error occurred in HandlerFeedInfo: %v", err)
+		}
+
+		record.NewestPostAt = &postStats.Newest
+		record.OldestPostAt = &postStats.Oldest
+		record.PostsPerWeek = float64(postStats.Recent) / (feedRateWindow.Hours() / (7 * 24))
+	}
+
+	getFeedFetchStatsParams := database.GetFeedFetchStatsParams{
+		FeedID: feed.ID,
+		Limit:  feedFetchHistory,
+	}
+
+	fetchStats, err := s.Db.GetFeedFetchStats(ctx, getFeedFetchStatsParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerFeedInfo: %v", err)
+	}
+	record.Fetches = fetchStats.Fetches
+	record.FailedFetches = fetchStats.Failures
+	record.AvgFetchMs = fetchStats.AvgDurationMs
+
+	lastError, err := s.Db.GetLastFeedFetchError(ctx, feed.ID)
+	if err == nil {
+		record.LastError = lastError.Error.String
+		record.LastErrorAt = &lastError.FetchedAt
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("unexpected error occurred in HandlerFeedInfo: %v", err)
+	}
+
+	if s.Out.Structured() {
+		return s.Out.Encode([]feedInfoRecord{record})
+	}
+
+	s.Out.Fields(
+		"Name", feed.Name,
+		"URL", feed.Url,
+		"Owner", owner,
+		"Followers", strconv.FormatInt(followers, 10),
+		"Posts", strconv.FormatInt(record.Posts, 10),
+		"Posts/week", fmt.Sprintf("%.1f (last %d weeks)", record.PostsPerWeek, int(feedRateWindow.Hours()/(7*24))),
+		"Newest post", formatOptionalTime(record.NewestPostAt),
+		"Oldest post", formatOptionalTime(record.OldestPostAt),
+		"Last fetched", agoOrNever(record.LastFetchedAt),
+		"Last error", formatLastError(record),
+		"Avg latency", formatFetchLatency(record),
+	)
+
+	return nil
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%s)", t.Format("2006-01-02"), output.Ago(*t))
+}
+
+func agoOrNever(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+	return output.Ago(*t)
+}
+
+func formatLastError(record feedInfoRecord) string {
+	if record.LastErrorAt == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%s (%s)", output.Truncate(record.LastError, 60), output.Ago(*record.LastErrorAt))
+}
+
+func formatFetchLatency(record feedInfoRecord) string {
+	if record.Fetches == 0 {
+		return "-"
+	}
+	latency := time.Duration(record.AvgFetchMs * float64(time.Millisecond)).Round(time.Millisecond)
+	return fmt.Sprintf("%v over the last %d fetch(es), %d failed", latency, record.Fetches, record.FailedFetches)
+}
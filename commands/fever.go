@@ -0,0 +1,419 @@
+package commands
+
+import (
+	"crypto/md5"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/auth"
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	feverAPIVersion = 3
+	feverPageSize   = 50
+	// feverGroupID is the single group every followed feed belongs to.
+	feverGroupID = 1
+)
+
+// feverKey is the api_key a Fever client sends: md5 of "email:password",
+// where gator expects the user's id as the email, which unlike their name
+// never changes, and an API key as the password.
+func feverKey(userID uuid.UUID, key string) string {
+	sum := md5.Sum([]byte(userID.String() + ":" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// authenticateFever resolves the user of the api_key form value.
+func authenticateFever(s *State, r *http.Request) (database.User, error) {
+	apiKey := strings.ToLower(r.FormValue("api_key"))
+	if apiKey == "" {
+		return database.User{}, apiError{http.StatusUnauthorized, "missing api_key"}
+	}
+
+	feverKeyHash := sql.NullString{String: auth.HashToken(apiKey), Valid: true}
+
+	user, err := s.Db.GetUserByFeverKey(r.Context(), feverKeyHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.User{}, apiError{http.StatusUnauthorized, "invalid api_key"}
+		}
+		return database.User{}, err
+	}
+
+	touchFeverKeyParams := database.TouchFeverKeyParams{
+		FeverKeyHash: feverKeyHash,
+		LastUsedAt:   sql.NullTime{Time: time.Now(), Valid: true},
+	}
+
+	err = s.Db.TouchFeverKey(r.Context(), touchFeverKeyParams)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	return user, nil
+}
+
+// handleFever serves the Fever API. Which parts are returned is chosen by
+// the presence of groups, feeds, items, unread_item_ids, saved_item_ids,
+// favicons and links, and a mark action is applied first.
+func (a *apiServer) handleFever(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response := map[string]any{"api_version": feverAPIVersion, "auth": 0}
+
+	user, err := a.authenticateFever(r)
+	if err != nil {
+		var apiErr apiError
+		if errors.As(err, &apiErr) && apiErr.status == http.StatusUnauthorized {
+			// Fever clients expect a 200 with auth set to 0
+			respondWithJSON(w, http.StatusOK, response)
+			return
+		}
+		respondWithErr(w, err)
+		return
+	}
+	response["auth"] = 1
+
+	has := func(name string) bool {
+		_, ok := r.Form[name]
+		return ok
+	}
+
+	if has("mark") {
+		if err := a.feverMark(r, user, response); err != nil {
+			respondWithErr(w, err)
+			return
+		}
+	}
+
+	feeds, err := a.s.Db.GetFollowedFeeds(r.Context(), user.ID)
+	if err != nil {
+		respondWithErr(w, err)
+		return
+	}
+
+	var lastRefreshed time.Time
+	for _, feed := range feeds {
+		if feed.LastFetchedAt.Valid && feed.LastFetchedAt.Time.After(lastRefreshed) {
+			lastRefreshed = feed.LastFetchedAt.Time
+		}
+	}
+	response["last_refreshed_on_time"] = unixOrZero(lastRefreshed)
+
+	if has("groups") {
+		response["groups"] = []map[string]any{{"id": feverGroupID, "title": "All"}}
+		response["feeds_groups"] = feverFeedsGroups(feeds)
+	}
+
+	if has("feeds") {
+		records := make([]map[string]any, len(feeds))
+		for i, feed := range feeds {
+			records[i] = map[string]any{
+				"id":                   feed.SeqID,
+				"favicon_id":           0,
+				"title":                feed.Name,
+				"url":                  feed.Url,
+				"site_url":             feed.Url,
+				"is_spark":             0,
+				"last_updated_on_time": unixOrZero(feed.LastFetchedAt.Time),
+			}
+		}
+		response["feeds"] = records
+		response["feeds_groups"] = feverFeedsGroups(feeds)
+	}
+
+	if has("favicons") {
+		response["favicons"] = []any{}
+	}
+
+	if has("links") {
+		response["links"] = []any{}
+	}
+
+	if has("items") {
+		if err := a.feverItems(r, user, response); err != nil {
+			respondWithErr(w, err)
+			return
+		}
+	}
+
+	if has("unread_item_ids") {
+		if err := a.feverUnreadItemIDs(r, user, response); err != nil {
+			respondWithErr(w, err)
+			return
+		}
+	}
+
+	if has("saved_item_ids") {
+		if err := a.feverSavedItemIDs(r, user, response); err != nil {
+			respondWithErr(w, err)
+			return
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func feverFeedsGroups(feeds []database.Feed) []map[string]any {
+	ids := make([]string, len(feeds))
+	for i, feed := range feeds {
+		ids[i] = strconv.FormatInt(feed.SeqID, 10)
+	}
+	return []map[string]any{{"group_id": feverGroupID, "feed_ids": strings.Join(ids, ",")}}
+}
+
+// feverItems returns up to 50 items after since_id (ascending), before
+// max_id (descending) or listed in with_ids. Muted posts are left out, so
+// clients paging by id skip over them.
+func (a *apiServer) feverItems(r *http.Request, user database.User, response map[string]any) error {
+	params := database.GetFeverItemsParams{
+		UserID: user.ID,
+	}
+
+	if value := r.FormValue("since_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return apiError{http.StatusBadRequest, "since_id must be an integer"}
+		}
+		params.SinceID = sql.NullInt64{Int64: id, Valid: true}
+	}
+
+	if value := r.FormValue("max_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return apiError{http.StatusBadRequest, "max_id must be an integer"}
+		}
+		params.MaxID = sql.NullInt64{Int64: id, Valid: true}
+	}
+
+	if value := r.FormValue("with_ids"); value != "" {
+		for _, field := range strings.Split(value, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+			if err != nil {
+				return apiError{http.StatusBadRequest, "with_ids must be a comma-separated list of integers"}
+			}
+			params.WithIds = append(params.WithIds, id)
+		}
+		if len(params.WithIds) > feverPageSize {
+			return apiError{http.StatusBadRequest, fmt.Sprintf("with_ids accepts at most %d ids", feverPageSize)}
+		}
+	}
+
+	filters, err := loadMuteFilters(r.Context(), a.s, user)
+	if err != nil {
+		return err
+	}
+
+	fetch := func(n int) ([]database.GetFeverItemsRow, error) {
+		params.PageLimit = int32(n)
+		return a.s.Db.GetFeverItems(r.Context(), params)
+	}
+
+	posts, _, err := fetchVisible(feverPageSize, fetch, func(post database.GetFeverItemsRow) bool {
+		return filters.hides(post.Title.String, post.Author.String, post.Categories, post.Url.String)
+	})
+	if err != nil {
+		return err
+	}
+
+	total, err := a.s.Db.CountFollowedPosts(r.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	items := make([]map[string]any, len(posts))
+	for i, post := range posts {
+		items[i] = map[string]any{
+			"id":              post.SeqID,
+			"feed_id":         post.FeedSeqID,
+			"title":           post.Title.String,
+			"author":          post.Author.String,
+			"html":            post.Description.String,
+			"url":             post.Url.String,
+			"is_saved":        boolInt(post.Starred),
+			"is_read":         boolInt(post.ReadAt.Valid),
+			"created_on_time": post.PublishedAt.Unix(),
+		}
+	}
+
+	response["items"] = items
+	response["total_items"] = total
+
+	return nil
+}
+
+// feverUnreadItemIDs and feverSavedItemIDs leave muted posts out, as
+// feverItems does, so clients never ask for items they cannot get.
+func (a *apiServer) feverUnreadItemIDs(r *http.Request, user database.User, response map[string]any) error {
+	filters, err := loadMuteFilters(r.Context(), a.s, user)
+	if err != nil {
+		return err
+	}
+
+	posts, err := a.s.Db.GetUnreadPostSeqIDs(r.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	ids := []int64{}
+	for _, post := range posts {
+		if !filters.hides(post.Title.String, post.Author.String, post.Categories, post.Url.String) {
+			ids = append(ids, post.SeqID)
+		}
+	}
+
+	response["unread_item_ids"] = joinInt64s(ids)
+	return nil
+}
+
+func (a *apiServer) feverSavedItemIDs(r *http.Request, user database.User, response map[string]any) error {
+	filters, err := loadMuteFilters(r.Context(), a.s, user)
+	if err != nil {
+		return err
+	}
+
+	posts, err := a.s.Db.GetStarredPostSeqIDs(r.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	ids := []int64{}
+	for _, post := range posts {
+		if !filters.hides(post.Title.String, post.Author.String, post.Categories, post.Url.String) {
+			ids = append(ids, post.SeqID)
+		}
+	}
+
+	response["saved_item_ids"] = joinInt64s(ids)
+	return nil
+}
+
+// feverMark applies mark=item (as read, unread, saved or unsaved) or
+// mark=feed/group (as read, for items fetched before "before"), and adds
+// the ids it changed to response.
+func (a *apiServer) feverMark(r *http.Request, user database.User, response map[string]any) error {
+	mark := r.FormValue("mark")
+	as := r.FormValue("as")
+
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		return apiError{http.StatusBadRequest, "id must be an integer"}
+	}
+
+	switch mark {
+	case "item":
+		getFollowedPostBySeqIDParams := database.GetFollowedPostBySeqIDParams{
+			UserID: user.ID,
+			SeqID:  id,
+		}
+
+		post, err := a.s.Db.GetFollowedPostBySeqID(r.Context(), getFollowedPostBySeqIDParams)
+		if errors.Is(err, sql.ErrNoRows) {
+			return apiError{http.StatusNotFound, fmt.Sprintf("item %d does not exist", id)}
+		} else if err != nil {
+			return err
+		}
+
+		switch as {
+		case "read":
+			err = a.s.Db.MarkPostRead(r.Context(), database.MarkPostReadParams{
+				UserID:    user.ID,
+				PostID:    post.ID,
+				CreatedAt: time.Now(),
+			})
+		case "unread":
+			err = a.s.Db.MarkPostUnread(r.Context(), database.MarkPostUnreadParams{
+				UserID:    user.ID,
+				PostID:    post.ID,
+				UpdatedAt: time.Now(),
+			})
+		case "saved", "unsaved":
+			err = a.s.Db.SetPostStarred(r.Context(), database.SetPostStarredParams{
+				UserID:    user.ID,
+				PostID:    post.ID,
+				CreatedAt: time.Now(),
+				Starred:   as == "saved",
+			})
+		default:
+			return apiError{http.StatusBadRequest, "as must be read, unread, saved or unsaved"}
+		}
+		if err != nil {
+			return err
+		}
+
+		if as == "saved" || as == "unsaved" {
+			return a.feverSavedItemIDs(r, user, response)
+		}
+		return a.feverUnreadItemIDs(r, user, response)
+
+	case "feed", "group":
+		if as != "read" {
+			return apiError{http.StatusBadRequest, fmt.Sprintf("%s can only be marked as read", mark)}
+		}
+
+		before, err := strconv.ParseInt(r.FormValue("before"), 10, 64)
+		if err != nil {
+			return apiError{http.StatusBadRequest, "before must be a Unix time"}
+		}
+
+		markPostsReadBeforeParams := database.MarkPostsReadBeforeParams{
+			UserID: user.ID,
+			ReadAt: time.Now(),
+			Before: time.Unix(before, 0),
+		}
+
+		// Every feed is in the one group, and group 0 is all items, so
+		// marking a group marks everything
+		if mark == "feed" {
+			feed, err := a.s.Db.GetFeedBySeqID(r.Context(), id)
+			if errors.Is(err, sql.ErrNoRows) {
+				return apiError{http.StatusNotFound, fmt.Sprintf("feed %d does not exist", id)}
+			} else if err != nil {
+				return err
+			}
+			markPostsReadBeforeParams.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+		}
+
+		err = a.s.Db.MarkPostsReadBefore(r.Context(), markPostsReadBeforeParams)
+		if err != nil {
+			return err
+		}
+
+		return a.feverUnreadItemIDs(r, user, response)
+	}
+
+	return apiError{http.StatusBadRequest, "mark must be item, feed or group"}
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func joinInt64s(ids []int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(parts, ",")
+}
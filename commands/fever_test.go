@@ -0,0 +1,9 @@
+package commands
+
+import "testing"
+
+// TestFeverAPI runs the request scripts in testdata/fever, written by hand
+// after the calls Fever clients such as Unread and Reeder make.
+func TestFeverAPI(t *testing.T) {
+	replayFixtures(t, "fever")
+}
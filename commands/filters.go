@@ -0,0 +1,251 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/Cmolloy36/gator/internal/match"
+	"github.com/Cmolloy36/gator/internal/output"
+	"github.com/google/uuid"
+)
+
+func HandlerFilter(s *State, cmd Command, user database.User) error {
+	switch cmd.Options.String("action") {
+	case "add":
+		return addFilter(s, cmd, user)
+	case "list":
+		return listFilters(s, user)
+	case "rm":
+		return removeFilter(s, cmd, user)
+	}
+	return nil
+}
+
+func addFilter(s *State, cmd Command, user database.User) error {
+	text := cmd.Options.String("pattern")
+	regex := cmd.Options.Bool("regex")
+	if text == "" {
+		return fmt.Errorf("error: \"filter add\" needs a pattern to hide posts by")
+	}
+
+	field := cmd.Options.String("field")
+
+	pattern, err := match.Compile(text, regex)
+	if err != nil {
+		return fmt.Errorf("error: %v", err)
+	}
+
+	createFilterParams := database.CreateFilterParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Field:     field,
+		Pattern:   text,
+		IsRegex:   regex,
+		CreatedAt: time.Now(),
+	}
+
+	filter, err := s.Db.CreateFilter(context.Background(), createFilterParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in addFilter: %v", err)
+	}
+
+	s.Out.Successf("Added filter %s hiding posts whose %s matches %s", shortPostID(filter.ID), filterFieldName(field), pattern)
+
+	return nil
+}
+
+func filterFieldName(field string) string {
+	if field == "any" {
+		return "title, author, category or URL"
+	}
+	return field
+}
+
+func listFilters(s *State, user database.User) error {
+	filters, err := s.Db.GetFiltersForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in listFilters: %v", err)
+	}
+
+	if s.Out.Structured() {
+		records := make([]filterRecord, len(filters))
+		for i, filter := range filters {
+			records[i] = newFilterRecord(filter)
+		}
+		return s.Out.Encode(records)
+	}
+
+	if len(filters) == 0 {
+		return fmt.Errorf("%s has no filters, add one with \"gator filter add <pattern>\"", user.Name)
+	}
+
+	table := output.NewTable("ID", "FIELD", "PATTERN", "CREATED").
+		SetStyle(0, output.Dim)
+	for _, filter := range filters {
+		table.Row(shortPostID(filter.ID), filter.Field, alertPattern(filter.Pattern, filter.IsRegex), output.Ago(filter.CreatedAt))
+	}
+	s.Out.Table(table)
+
+	return nil
+}
+
+func removeFilter(s *State, cmd Command, user database.User) error {
+	ref := cmd.Options.String("pattern")
+	if ref == "" {
+		return fmt.Errorf("error: \"filter rm\" needs the id or pattern of a filter (see \"gator filter list\")")
+	}
+
+	filters, err := s.Db.GetFiltersForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in removeFilter: %v", err)
+	}
+
+	matches := []database.Filter{}
+	for _, filter := range filters {
+		if filter.Pattern == ref || strings.HasPrefix(filter.ID.String(), strings.ToLower(ref)) {
+			matches = append(matches, filter)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("%s has no filter %s", user.Name, ref)
+	case 1:
+	default:
+		return fmt.Errorf("filter %s is ambiguous, use its id from \"gator filter list\"", ref)
+	}
+
+	deleteFilterParams := database.DeleteFilterParams{
+		ID:     matches[0].ID,
+		UserID: user.ID,
+	}
+
+	err = s.Db.DeleteFilter(context.Background(), deleteFilterParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in removeFilter: %v", err)
+	}
+
+	s.Out.Successf("Removed filter %s (%s)", shortPostID(matches[0].ID), alertPattern(matches[0].Pattern, matches[0].IsRegex))
+
+	return nil
+}
+
+// muteFilter hides posts whose field matches pattern. The field is one of
+// title, author, category or url, or "any" for all of them.
+type muteFilter struct {
+	field   string
+	pattern *match.Pattern
+}
+
+// muteFilters are a user's filters, compiled. A post is hidden when any
+// of them matches.
+type muteFilters []muteFilter
+
+func loadMuteFilters(ctx context.Context, s *State, user database.User) (muteFilters, error) {
+	filters, err := s.Db.GetFiltersForUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := muteFilters{}
+	for _, filter := range filters {
+		pattern, err := match.Compile(filter.Pattern, filter.IsRegex)
+		if err != nil {
+			// Patterns are checked by "filter add", so this is unexpected
+			continue
+		}
+		compiled = append(compiled, muteFilter{field: filter.Field, pattern: pattern})
+	}
+
+	return compiled, nil
+}
+
+// hides reports whether a post with these fields is muted.
+func (f muteFilters) hides(title, author string, categories []string, url string) bool {
+	for _, filter := range f {
+		fields := []string{}
+		switch filter.field {
+		case "title":
+			fields = append(fields, title)
+		case "author":
+			fields = append(fields, author)
+		case "category":
+			fields = append(fields, categories...)
+		case "url":
+			fields = append(fields, url)
+		default:
+			fields = append(append(fields, title, author, url), categories...)
+		}
+
+		if filter.pattern.Match(fields...) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxFetchDoublings bounds how far fetchVisible looks past hidden rows:
+// it stops at limit<<maxFetchDoublings rows, so a filter that hides nearly
+// everything cannot make it read a user's whole history.
+const maxFetchDoublings = 5
+
+// fetchVisible returns the first limit rows that hidden lets through and
+// how many rows it skipped on the way. fetch is asked for more rows, with
+// the same ordering, until limit is reached, there are none left or
+// maxFetchDoublings is reached, in which case fewer than limit rows are
+// returned and skipped counts every row fetched but not returned.
+func fetchVisible[T any](limit int, fetch func(n int) ([]T, error), hidden func(T) bool) ([]T, int, error) {
+	for n, doublings := limit, 0; ; n, doublings = n*2, doublings+1 {
+		rows, err := fetch(n)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		visible := []T{}
+		skipped := 0
+		for _, row := range rows {
+			if len(visible) == limit {
+				break
+			}
+			if hidden(row) {
+				skipped++
+				continue
+			}
+			visible = append(visible, row)
+		}
+
+		if len(visible) == limit || len(rows) < n || doublings == maxFetchDoublings {
+			return visible, skipped, nil
+		}
+	}
+}
+
+// getVisiblePosts returns the user's browse listing with muted posts left
+// out, along with the number of posts hidden.
+func getVisiblePosts(s *State, user database.User, limit int) ([]database.GetPostsForUserRow, int, error) {
+	filters, err := loadMuteFilters(context.Background(), s, user)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	fetch := func(n int) ([]database.GetPostsForUserRow, error) {
+		getPostsForUserParams := database.GetPostsForUserParams{
+			UserID: user.ID,
+			Limit:  int32(n),
+		}
+		return s.Db.GetPostsForUser(context.Background(), getPostsForUserParams)
+	}
+
+	return fetchVisible(limit, fetch, func(post database.GetPostsForUserRow) bool {
+		return filters.hides(post.Title.String, post.Author.String, post.Categories, post.Url.String)
+	})
+}
+
+// hiddenNotice tells the user how many posts their filters left out of a
+// listing.
+func hiddenNotice(hidden int) string {
+	return fmt.Sprintf("%d post(s) hidden by your filters (see \"gator filter list\")", hidden)
+}
@@ -0,0 +1,45 @@
+package commands
+
+import "testing"
+
+// TestFetchVisible checks that fetchVisible fills the limit past hidden
+// rows, and gives up after maxFetchDoublings when nearly every row is
+// hidden.
+func TestFetchVisible(t *testing.T) {
+	tests := []struct {
+		name        string
+		rows        int
+		visibleFrom int
+		wantVisible int
+		wantSkipped int
+		wantFetches int
+	}{
+		{"nothing hidden", 100, 0, 10, 0, 1},
+		{"filled past hidden rows", 100, 15, 10, 15, 3},
+		{"fewer rows than the limit", 12, 8, 4, 8, 2},
+		{"capped", 10000, 5000, 0, 10 << maxFetchDoublings, maxFetchDoublings + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetches := 0
+			fetch := func(n int) ([]int, error) {
+				fetches++
+				rows := []int{}
+				for i := 0; i < min(n, tt.rows); i++ {
+					rows = append(rows, i)
+				}
+				return rows, nil
+			}
+
+			visible, skipped, err := fetchVisible(10, fetch, func(row int) bool { return row < tt.visibleFrom })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(visible) != tt.wantVisible || skipped != tt.wantSkipped || fetches != tt.wantFetches {
+				t.Errorf("got %d visible, %d skipped in %d fetches, want %d, %d in %d",
+					len(visible), skipped, fetches, tt.wantVisible, tt.wantSkipped, tt.wantFetches)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/output"
+)
+
+type OptionKind int
+
+const (
+	StringOption OptionKind = iota
+	BoolOption
+	IntOption
+	DurationOption
+)
+
+func (k OptionKind) String() string {
+	switch k {
+	case BoolOption:
+		return "bool"
+	case IntOption:
+		return "int"
+	case DurationOption:
+		return "duration"
+	}
+	return "string"
+}
+
+// Arg declares a positional argument. Positional arguments are matched in
+// declaration order; only trailing arguments may be optional. When Values
+// is set the argument must be one of them.
+type Arg struct {
+	Name     string
+	Kind     OptionKind
+	Optional bool
+	Default  string
+	Values   []string
+	Complete Completion
+}
+
+// Flag declares a named option accepted as --name value, --name=value or,
+// when Short is set, -s value. Bool flags take no value.
+type Flag struct {
+	Name     string
+	Short    string
+	Kind     OptionKind
+	Default  string
+	Usage    string
+	Values   []string
+	Complete Completion
+}
+
+// OutputFlags are accepted by listing commands and configure State.Out.
+var OutputFlags = []Flag{
+	{Name: "output", Short: "o", Usage: "Output format: text, json, csv or yaml", Default: "text", Values: []string{"text", "json", "csv", "yaml"}},
+	{Name: "format", Usage: "Go template rendered once per record"},
+}
+
+// YesFlag is accepted by destructive commands to skip their confirmation
+// prompt.
+var YesFlag = Flag{Name: "yes", Short: "y", Kind: BoolOption, Usage: "Do not ask for confirmation"}
+
+// Options holds the validated positional arguments and flags of a command,
+// keyed by name. A flag and a positional argument may share a name, in
+// which case either form sets the same option.
+type Options struct {
+	values map[string]string
+	set    map[string]bool
+}
+
+func (o Options) IsSet(name string) bool {
+	return o.set[name]
+}
+
+func (o Options) String(name string) string {
+	return o.values[name]
+}
+
+func (o Options) Bool(name string) bool {
+	b, _ := strconv.ParseBool(o.values[name])
+	return b
+}
+
+func (o Options) Int(name string) int {
+	n, _ := strconv.Atoi(o.values[name])
+	return n
+}
+
+func (o Options) Duration(name string) time.Duration {
+	d, _ := time.ParseDuration(o.values[name])
+	return d
+}
+
+// Synopsis describes the positional arguments, e.g. "<name> <url> [limit]".
+func (info CommandInfo) Synopsis() string {
+	parts := []string{}
+	if len(info.Flags) > 0 {
+		parts = append(parts, "[flags]")
+	}
+	for _, arg := range info.Args {
+		if arg.Optional {
+			parts = append(parts, "["+arg.Name+"]")
+		} else {
+			parts = append(parts, "<"+arg.Name+">")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (info CommandInfo) lookupFlag(name string) (Flag, bool) {
+	for _, flag := range info.Flags {
+		if flag.Name == name || (flag.Short != "" && flag.Short == name) {
+			return flag, true
+		}
+	}
+	return Flag{}, false
+}
+
+// parseOptions splits raw into flags and positional arguments according to
+// info, validating counts and types. "--" ends flag parsing.
+func parseOptions(name string, info CommandInfo, raw []string) (Options, []string, error) {
+	opts := Options{
+		values: map[string]string{},
+		set:    map[string]bool{},
+	}
+
+	usage := fmt.Sprintf("(usage: gator %s)", strings.TrimSpace(name+" "+info.Synopsis()))
+
+	for _, flag := range info.Flags {
+		if flag.Default != "" {
+			opts.values[flag.Name] = flag.Default
+		} else if flag.Kind == BoolOption {
+			opts.values[flag.Name] = "false"
+		}
+	}
+	for _, arg := range info.Args {
+		if arg.Default != "" {
+			opts.values[arg.Name] = arg.Default
+		}
+	}
+
+	positional := []string{}
+	for i := 0; i < len(raw); i++ {
+		token := raw[i]
+
+		if token == "--" {
+			positional = append(positional, raw[i+1:]...)
+			break
+		}
+
+		if !strings.HasPrefix(token, "-") || token == "-" || isNegativeNumber(token) {
+			positional = append(positional, token)
+			continue
+		}
+
+		flagName, value, hasValue := strings.Cut(strings.TrimLeft(token, "-"), "=")
+		flag, ok := info.lookupFlag(flagName)
+		if !ok {
+			return opts, nil, fmt.Errorf("error: \"%s\" does not accept flag %s %s", name, token, usage)
+		}
+
+		if flag.Kind == BoolOption && !hasValue {
+			value = "true"
+		} else if !hasValue {
+			if i+1 >= len(raw) {
+				return opts, nil, fmt.Errorf("error: flag --%s of \"%s\" expects a value (%s)", flag.Name, name, flag.Kind)
+			}
+			i++
+			value = raw[i]
+		}
+
+		if err := checkValue(flag.Kind, flag.Values, value); err != nil {
+			return opts, nil, fmt.Errorf("error: invalid value \"%s\" for flag --%s of \"%s\": %v", value, flag.Name, name, err)
+		}
+
+		opts.values[flag.Name] = value
+		opts.set[flag.Name] = true
+	}
+
+	required := 0
+	for _, arg := range info.Args {
+		if !arg.Optional {
+			required++
+		}
+	}
+
+	if len(positional) > len(info.Args) {
+		if len(info.Args) == 0 {
+			return opts, nil, fmt.Errorf("error: \"%s\" does not expect any arguments %s", name, usage)
+		}
+		return opts, nil, fmt.Errorf("error: \"%s\" expects at most %d argument(s) %s", name, len(info.Args), usage)
+	}
+
+	if len(positional) < required {
+		missing := info.Args[len(positional)]
+		return opts, nil, fmt.Errorf("error: \"%s\" is missing argument <%s> %s", name, missing.Name, usage)
+	}
+
+	for i, value := range positional {
+		arg := info.Args[i]
+
+		if opts.set[arg.Name] {
+			return opts, nil, fmt.Errorf("error: %s was given both as an argument and as --%s to \"%s\"", arg.Name, arg.Name, name)
+		}
+
+		if err := checkValue(arg.Kind, arg.Values, value); err != nil {
+			return opts, nil, fmt.Errorf("error: invalid value \"%s\" for <%s> of \"%s\": %v", value, arg.Name, name, err)
+		}
+
+		opts.values[arg.Name] = value
+		opts.set[arg.Name] = true
+	}
+
+	return opts, positional, nil
+}
+
+func checkValue(kind OptionKind, values []string, value string) error {
+	if len(values) > 0 && !slices.Contains(values, value) {
+		return fmt.Errorf("expected one of %s", strings.Join(values, ", "))
+	}
+
+	switch kind {
+	case BoolOption:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("expected true or false")
+		}
+	case IntOption:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("expected an integer")
+		}
+	case DurationOption:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("expected a duration such as 30s, 5m or 1h")
+		}
+	}
+	return nil
+}
+
+func isNegativeNumber(token string) bool {
+	_, err := strconv.Atoi(token)
+	return err == nil
+}
+
+// applyOutputOptions configures s.Out from the listing flags of a command,
+// resetting it first so a previous command's choice does not leak.
+func applyOutputOptions(s *State, opts Options) error {
+	s.Out.Reset()
+
+	if opts.IsSet("output") && opts.IsSet("format") {
+		return fmt.Errorf("error: --output and --format cannot be used together")
+	}
+
+	if opts.IsSet("output") {
+		format, err := output.ParseFormat(opts.String("output"))
+		if err != nil {
+			return err
+		}
+		s.Out.SetFormat(format)
+	}
+
+	if opts.IsSet("format") {
+		if err := s.Out.SetTemplate(opts.String("format")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,622 @@
+package commands
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/auth"
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// Stream ids and tags of the Google Reader API that gator understands.
+// Clients may also spell "user/-/" with their own user id.
+const (
+	readerReadingList = "user/-/state/com.google/reading-list"
+	readerRead        = "user/-/state/com.google/read"
+	readerStarred     = "user/-/state/com.google/starred"
+	readerFeedPrefix  = "feed/"
+	readerItemPrefix  = "tag:google.com,2005:reader/item/"
+
+	readerDefaultPageSize = 20
+	readerMaxPageSize     = 1000
+	// readerMaxItemIDs is the largest page of stream/items/ids, which
+	// clients use to sync read and starred state in bulk.
+	readerMaxItemIDs = 10000
+)
+
+// readerRoutes adds the subset of the Google Reader API spoken by FreshRSS
+// compatible clients.
+func (a *apiServer) readerRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/accounts/ClientLogin", a.handleReaderLogin)
+
+	mux.HandleFunc("GET /reader/api/0/token", a.readerAuthed(RoleReadOnly, a.handleReaderToken))
+	mux.HandleFunc("GET /reader/api/0/user-info", a.readerAuthed(RoleReadOnly, a.handleReaderUserInfo))
+	mux.HandleFunc("GET /reader/api/0/subscription/list", a.readerAuthed(RoleReadOnly, a.handleReaderSubscriptions))
+	mux.HandleFunc("GET /reader/api/0/tag/list", a.readerAuthed(RoleReadOnly, a.handleReaderTags))
+	mux.HandleFunc("GET /reader/api/0/stream/contents", a.readerAuthed(RoleReadOnly, a.handleReaderStream))
+	mux.HandleFunc("GET /reader/api/0/stream/contents/{stream...}", a.readerAuthed(RoleReadOnly, a.handleReaderStream))
+	mux.HandleFunc("GET /reader/api/0/stream/items/ids", a.readerAuthed(RoleReadOnly, a.handleReaderItemIDs))
+	mux.HandleFunc("GET /reader/api/0/stream/items/contents", a.readerAuthed(RoleReadOnly, a.handleReaderItemContents))
+	mux.HandleFunc("POST /reader/api/0/stream/items/contents", a.readerAuthed(RoleReadOnly, a.handleReaderItemContents))
+	mux.HandleFunc("POST /reader/api/0/edit-tag", a.readerAuthed(RoleReadOnly, a.handleReaderEditTag))
+}
+
+// readerAuthed is authed for the Reader API, which answers in plain text.
+func (a *apiServer) readerAuthed(role string, handler func(w http.ResponseWriter, r *http.Request, user database.User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := a.authenticateReader(r)
+		if err != nil {
+			respondWithReaderErr(w, err)
+			return
+		}
+
+		if !hasRole(user, role) {
+			http.Error(w, fmt.Sprintf("requires the %s role", role), http.StatusForbidden)
+			return
+		}
+
+		handler(w, r, user)
+	}
+}
+
+func respondWithReaderErr(w http.ResponseWriter, err error) {
+	var apiErr apiError
+	if errors.As(err, &apiErr) {
+		http.Error(w, apiErr.message, apiErr.status)
+		return
+	}
+	log.Printf("unexpected error serving the Reader API: %v", err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// authenticateReader resolves the user of an "Authorization: GoogleLogin
+// auth=<token>" header, where the token is an API key or was issued by
+// ClientLogin.
+func authenticateReader(s *State, r *http.Request) (database.User, error) {
+	token := readerAuthToken(r)
+	if token == "" {
+		return database.User{}, apiError{http.StatusUnauthorized, "missing \"Authorization: GoogleLogin auth=<token>\" header"}
+	}
+
+	user, err := userByAPIKey(r.Context(), s, token, apiKeyScopeFull)
+	var apiErr apiError
+	if err == nil || !errors.As(err, &apiErr) {
+		return user, err
+	}
+
+	getUserBySessionParams := database.GetUserBySessionParams{
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now(),
+	}
+
+	user, err = s.Db.GetUserBySession(r.Context(), getUserBySessionParams)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, apiError{http.StatusUnauthorized, "invalid or expired token"}
+	}
+	return user, err
+}
+
+func readerAuthToken(r *http.Request) string {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "GoogleLogin auth=")
+	return token
+}
+
+// readerActionToken derives the T token for an auth token. It changes with
+// the auth token, so it is only valid alongside the credentials it was
+// issued for.
+func readerActionToken(authToken string) string {
+	return auth.HashToken("reader-action:" + authToken)
+}
+
+// handleReaderLogin implements ClientLogin. Passwd may be the user's
+// password, which starts a session, or one of their API keys, which is
+// handed back as the token.
+func (a *apiServer) handleReaderLogin(w http.ResponseWriter, r *http.Request) {
+	name := r.FormValue("Email")
+	password := r.FormValue("Passwd")
+
+	token, err := a.readerLogin(r.Context(), name, password)
+	if err != nil {
+		respondWithReaderErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "SID=%s\nLSID=null\nAuth=%s\n", token, token)
+}
+
+func (a *apiServer) readerLogin(ctx context.Context, name, password string) (string, error) {
+	// Google answered bad credentials with 403, which clients expect
+	badAuth := apiError{http.StatusForbidden, "Error=BadAuthentication"}
+
+	if name == "" || password == "" {
+		return "", badAuth
+	}
+
+	user, err := a.s.Db.GetUser(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", badAuth
+	} else if err != nil {
+		return "", err
+	}
+
+	if strings.HasPrefix(password, apiKeyPrefix) {
+		owner, err := userByAPIKey(ctx, a.s, password, apiKeyScopeFull)
+		if err == nil && owner.ID == user.ID {
+			return password, nil
+		}
+	}
+
+	if !user.PasswordHash.Valid || auth.CheckPassword(user.PasswordHash.String, password) != nil {
+		return "", badAuth
+	}
+
+	token, _, err := createSession(ctx, a.s, user, defaultSessionTTL)
+	return token, err
+}
+
+// handleReaderToken returns the token clients send back as T on edits.
+func (a *apiServer) handleReaderToken(w http.ResponseWriter, r *http.Request, user database.User) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, readerActionToken(readerAuthToken(r)))
+}
+
+// checkReaderActionToken rejects edits without the T token of the request's
+// credentials. Clients fetch a new token when they see
+// X-Reader-Google-Bad-Token.
+func checkReaderActionToken(w http.ResponseWriter, r *http.Request) bool {
+	want := readerActionToken(readerAuthToken(r))
+	if subtle.ConstantTimeCompare([]byte(r.Form.Get("T")), []byte(want)) == 1 {
+		return true
+	}
+
+	w.Header().Set("X-Reader-Google-Bad-Token", "true")
+	http.Error(w, "invalid or missing T token (see /reader/api/0/token)", http.StatusUnauthorized)
+	return false
+}
+
+func (a *apiServer) handleReaderUserInfo(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"userId":        user.ID.String(),
+		"userName":      user.Name,
+		"userProfileId": user.ID.String(),
+		"userEmail":     "",
+	})
+}
+
+type readerSubscription struct {
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	Categories []string `json:"categories"`
+	Url        string   `json:"url"`
+	HtmlUrl    string   `json:"htmlUrl"`
+	IconUrl    string   `json:"iconUrl"`
+}
+
+func (a *apiServer) handleReaderSubscriptions(w http.ResponseWriter, r *http.Request, user database.User) {
+	follows, err := a.s.Db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithReaderErr(w, err)
+		return
+	}
+
+	subscriptions := make([]readerSubscription, len(follows))
+	for i, follow := range follows {
+		subscriptions[i] = readerSubscription{
+			ID:         readerFeedPrefix + follow.FeedUrl,
+			Title:      follow.FeedName,
+			Categories: []string{},
+			Url:        follow.FeedUrl,
+			HtmlUrl:    follow.FeedUrl,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]any{"subscriptions": subscriptions})
+}
+
+func (a *apiServer) handleReaderTags(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondWithJSON(w, http.StatusOK, map[string]any{
+		"tags": []map[string]string{{"id": readerStarred}},
+	})
+}
+
+type readerLink struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+type readerItem struct {
+	ID            string            `json:"id"`
+	CrawlTimeMsec string            `json:"crawlTimeMsec"`
+	TimestampUsec string            `json:"timestampUsec"`
+	Published     int64             `json:"published"`
+	Updated       int64             `json:"updated"`
+	Title         string            `json:"title"`
+	Canonical     []readerLink      `json:"canonical"`
+	Alternate     []readerLink      `json:"alternate"`
+	Summary       map[string]string `json:"summary"`
+	Categories    []string          `json:"categories"`
+	Origin        map[string]string `json:"origin"`
+}
+
+func newReaderItem(post database.GetPostsForReaderStreamRow) readerItem {
+	categories := []string{readerReadingList}
+	if post.ReadAt.Valid {
+		categories = append(categories, readerRead)
+	}
+	if post.Starred {
+		categories = append(categories, readerStarred)
+	}
+
+	return readerItem{
+		ID:            fmt.Sprintf("%s%016x", readerItemPrefix, post.SeqID),
+		CrawlTimeMsec: strconv.FormatInt(post.CreatedAt.UnixMilli(), 10),
+		TimestampUsec: strconv.FormatInt(post.PublishedAt.UnixMicro(), 10),
+		Published:     post.PublishedAt.Unix(),
+		Updated:       post.UpdatedAt.Unix(),
+		Title:         post.Title.String,
+		Canonical:     []readerLink{{Href: post.Url.String}},
+		Alternate:     []readerLink{{Href: post.Url.String, Type: "text/html"}},
+		Summary:       map[string]string{"direction": "ltr", "content": post.Description.String},
+		Categories:    categories,
+		Origin: map[string]string{
+			"streamId": readerFeedPrefix + post.FeedUrl,
+			"title":    post.FeedName,
+			"htmlUrl":  post.FeedUrl,
+		},
+	}
+}
+
+// readerStreamParams parses the stream and paging parameters shared by
+// stream/contents and stream/items/ids: n, c (continuation), ot/nt
+// (newer/older than, in seconds), xt and it for the read and starred tags,
+// and r=o for oldest first. The stream is the reading list, starred items
+// or one feed.
+func (a *apiServer) readerStreamParams(r *http.Request, user database.User, maxPageSize int) (string, database.GetPostsForReaderStreamParams, error) {
+	query := r.URL.Query()
+
+	streamID := r.PathValue("stream")
+	if streamID == "" {
+		streamID = query.Get("s")
+	}
+	if streamID == "" {
+		streamID = readerReadingList
+	}
+
+	params := database.GetPostsForReaderStreamParams{
+		UserID:      user.ID,
+		UnreadOnly:  readerTag(query.Get("xt")) == readerRead,
+		StarredOnly: readerTag(query.Get("it")) == readerStarred,
+		OldestFirst: query.Get("r") == "o",
+	}
+
+	switch tag := readerTag(streamID); {
+	case tag == readerReadingList:
+	case tag == readerStarred:
+		params.StarredOnly = true
+	case strings.HasPrefix(streamID, readerFeedPrefix):
+		feed, err := a.s.Db.GetFeed(r.Context(), strings.TrimPrefix(streamID, readerFeedPrefix))
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", params, apiError{http.StatusNotFound, fmt.Sprintf("stream %s does not exist", streamID)}
+		} else if err != nil {
+			return "", params, err
+		}
+		params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	default:
+		return "", params, apiError{http.StatusBadRequest, fmt.Sprintf("unsupported stream %s", streamID)}
+	}
+
+	limit, err := queryInt(r, "n", readerDefaultPageSize)
+	if err != nil {
+		return "", params, err
+	}
+	if limit < 1 || limit > maxPageSize {
+		return "", params, apiError{http.StatusBadRequest, fmt.Sprintf("n must be between 1 and %d", maxPageSize)}
+	}
+
+	offset, err := queryInt(r, "c", 0)
+	if err != nil {
+		return "", params, err
+	}
+
+	since, err := queryInt(r, "ot", 0)
+	if err != nil {
+		return "", params, err
+	}
+	if since > 0 {
+		params.Since = sql.NullTime{Time: time.Unix(int64(since), 0), Valid: true}
+	}
+
+	until, err := queryInt(r, "nt", 0)
+	if err != nil {
+		return "", params, err
+	}
+	if until > 0 {
+		params.Until = sql.NullTime{Time: time.Unix(int64(until), 0), Valid: true}
+	}
+
+	params.PageLimit = int32(limit)
+	params.PageOffset = int32(offset)
+
+	return streamID, params, nil
+}
+
+// readerVisiblePosts returns the page of the stream params describes with
+// the user's muted posts left out, filled up to n with visible ones, and
+// the c of the next page, or "" after the last. Continuations count the
+// hidden posts, so the next page starts after them. A page cut short by
+// maxFetchDoublings still has a continuation.
+func (a *apiServer) readerVisiblePosts(ctx context.Context, user database.User, params database.GetPostsForReaderStreamParams) ([]database.GetPostsForReaderStreamRow, string, error) {
+	filters, err := loadMuteFilters(ctx, a.s, user)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fetch := func(n int) ([]database.GetPostsForReaderStreamRow, error) {
+		page := params
+		page.PageLimit = int32(n)
+		return a.s.Db.GetPostsForReaderStream(ctx, page)
+	}
+
+	posts, skipped, err := fetchVisible(int(params.PageLimit), fetch, func(post database.GetPostsForReaderStreamRow) bool {
+		return filters.hides(post.Title.String, post.Author.String, post.Categories, post.Url.String)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	limit := int(params.PageLimit)
+	if len(posts) < limit && len(posts)+skipped < limit<<maxFetchDoublings {
+		return posts, "", nil
+	}
+	return posts, strconv.Itoa(int(params.PageOffset) + len(posts) + skipped), nil
+}
+
+// handleReaderStream serves stream/contents, see readerStreamParams.
+func (a *apiServer) handleReaderStream(w http.ResponseWriter, r *http.Request, user database.User) {
+	streamID, params, err := a.readerStreamParams(r, user, readerMaxPageSize)
+	if err != nil {
+		respondWithReaderErr(w, err)
+		return
+	}
+
+	posts, continuation, err := a.readerVisiblePosts(r.Context(), user, params)
+	if err != nil {
+		respondWithReaderErr(w, err)
+		return
+	}
+
+	items := make([]readerItem, len(posts))
+	for i, post := range posts {
+		items[i] = newReaderItem(post)
+	}
+
+	response := map[string]any{
+		"id":      streamID,
+		"updated": time.Now().Unix(),
+		"items":   items,
+	}
+	if continuation != "" {
+		response["continuation"] = continuation
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+type readerItemRef struct {
+	ID              string   `json:"id"`
+	DirectStreamIds []string `json:"directStreamIds"`
+	TimestampUsec   string   `json:"timestampUsec"`
+}
+
+// handleReaderItemIDs serves stream/items/ids, the short ids of a stream's
+// items, which clients use to sync unread and starred items without
+// downloading them. It takes the parameters of stream/contents.
+func (a *apiServer) handleReaderItemIDs(w http.ResponseWriter, r *http.Request, user database.User) {
+	_, params, err := a.readerStreamParams(r, user, readerMaxItemIDs)
+	if err != nil {
+		respondWithReaderErr(w, err)
+		return
+	}
+
+	posts, continuation, err := a.readerVisiblePosts(r.Context(), user, params)
+	if err != nil {
+		respondWithReaderErr(w, err)
+		return
+	}
+
+	refs := make([]readerItemRef, len(posts))
+	for i, post := range posts {
+		refs[i] = readerItemRef{
+			ID:              strconv.FormatInt(post.SeqID, 10),
+			DirectStreamIds: []string{},
+			TimestampUsec:   strconv.FormatInt(post.PublishedAt.UnixMicro(), 10),
+		}
+	}
+
+	response := map[string]any{"itemRefs": refs}
+	if continuation != "" {
+		response["continuation"] = continuation
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// handleReaderItemContents serves stream/items/contents, the items listed
+// as i, in either id form. Items from feeds the user does not follow and
+// muted items are left out.
+func (a *apiServer) handleReaderItemContents(w http.ResponseWriter, r *http.Request, user database.User) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	itemIDs := r.Form["i"]
+	if len(itemIDs) > readerMaxPageSize {
+		http.Error(w, fmt.Sprintf("at most %d items can be requested at once", readerMaxPageSize), http.StatusBadRequest)
+		return
+	}
+
+	seqIDs := make([]int64, len(itemIDs))
+	for i, itemID := range itemIDs {
+		seqID, err := parseReaderItemID(itemID)
+		if err != nil {
+			respondWithReaderErr(w, err)
+			return
+		}
+		seqIDs[i] = seqID
+	}
+
+	getReaderItemsParams := database.GetReaderItemsParams{
+		UserID: user.ID,
+		SeqIds: seqIDs,
+	}
+
+	posts, err := a.s.Db.GetReaderItems(r.Context(), getReaderItemsParams)
+	if err != nil {
+		respondWithReaderErr(w, err)
+		return
+	}
+
+	filters, err := loadMuteFilters(r.Context(), a.s, user)
+	if err != nil {
+		respondWithReaderErr(w, err)
+		return
+	}
+
+	items := []readerItem{}
+	for _, post := range posts {
+		if filters.hides(post.Title.String, post.Author.String, post.Categories, post.Url.String) {
+			continue
+		}
+		items = append(items, newReaderItem(database.GetPostsForReaderStreamRow(post)))
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]any{
+		"id":      readerReadingList,
+		"updated": time.Now().Unix(),
+		"items":   items,
+	})
+}
+
+// handleReaderEditTag adds (a) or removes (r) the read and starred tags on
+// the items listed as i. Other tags are ignored. The request must carry the
+// T token from handleReaderToken, and nothing is changed unless every item
+// exists.
+func (a *apiServer) handleReaderEditTag(w http.ResponseWriter, r *http.Request, user database.User) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !checkReaderActionToken(w, r) {
+		return
+	}
+
+	// Resolve every item first, so an unknown one leaves all of them as
+	// they were
+	posts := make([]database.Post, len(r.Form["i"]))
+	for i, itemID := range r.Form["i"] {
+		seqID, err := parseReaderItemID(itemID)
+		if err != nil {
+			respondWithReaderErr(w, err)
+			return
+		}
+
+		getFollowedPostBySeqIDParams := database.GetFollowedPostBySeqIDParams{
+			UserID: user.ID,
+			SeqID:  seqID,
+		}
+
+		posts[i], err = a.s.Db.GetFollowedPostBySeqID(r.Context(), getFollowedPostBySeqIDParams)
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, fmt.Sprintf("item %s does not exist", itemID), http.StatusNotFound)
+			return
+		} else if err != nil {
+			respondWithReaderErr(w, err)
+			return
+		}
+	}
+
+	for _, post := range posts {
+		for _, tag := range r.Form["a"] {
+			if err := a.setReaderTag(r.Context(), user, post, readerTag(tag), true); err != nil {
+				respondWithReaderErr(w, err)
+				return
+			}
+		}
+		for _, tag := range r.Form["r"] {
+			if err := a.setReaderTag(r.Context(), user, post, readerTag(tag), false); err != nil {
+				respondWithReaderErr(w, err)
+				return
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, "OK")
+}
+
+func (a *apiServer) setReaderTag(ctx context.Context, user database.User, post database.Post, tag string, on bool) error {
+	switch {
+	case tag == readerRead && on:
+		return a.s.Db.MarkPostRead(ctx, database.MarkPostReadParams{
+			UserID:    user.ID,
+			PostID:    post.ID,
+			CreatedAt: time.Now(),
+		})
+	case tag == readerRead:
+		return a.s.Db.MarkPostUnread(ctx, database.MarkPostUnreadParams{
+			UserID:    user.ID,
+			PostID:    post.ID,
+			UpdatedAt: time.Now(),
+		})
+	case tag == readerStarred:
+		return a.s.Db.SetPostStarred(ctx, database.SetPostStarredParams{
+			UserID:    user.ID,
+			PostID:    post.ID,
+			CreatedAt: time.Now(),
+			Starred:   on,
+		})
+	}
+	return nil
+}
+
+// readerTag rewrites "user/<id>/..." tags to the "user/-/..." form.
+func readerTag(tag string) string {
+	rest, ok := strings.CutPrefix(tag, "user/")
+	if !ok {
+		return tag
+	}
+	_, rest, _ = strings.Cut(rest, "/")
+	return "user/-/" + rest
+}
+
+// parseReaderItemID accepts the long hexadecimal form of an item id and the
+// short decimal form.
+func parseReaderItemID(itemID string) (int64, error) {
+	invalid := apiError{http.StatusBadRequest, fmt.Sprintf("invalid item id %s", itemID)}
+
+	if hex, ok := strings.CutPrefix(itemID, readerItemPrefix); ok {
+		n, err := strconv.ParseUint(hex, 16, 64)
+		if err != nil {
+			return 0, invalid
+		}
+		return int64(n), nil
+	}
+
+	n, err := strconv.ParseInt(itemID, 10, 64)
+	if err != nil {
+		return 0, invalid
+	}
+	return n, nil
+}
@@ -0,0 +1,10 @@
+package commands
+
+import "testing"
+
+// TestReaderAPI runs the request scripts in testdata/greader. They are
+// written by hand after the calls Reader clients such as Reeder and
+// NetNewsWire make, not captured from them.
+func TestReaderAPI(t *testing.T) {
+	replayFixtures(t, "greader")
+}
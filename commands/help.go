@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Cmolloy36/gator/internal/output"
+)
+
+// maxSuggestionDistance is the largest edit distance at which an unknown
+// command name is still considered a typo of a registered one.
+const maxSuggestionDistance = 2
+
+// CommandInfo documents a registered command for help and usage output.
+type CommandInfo struct {
+	Description   string
+	Args          []Arg
+	Flags         []Flag
+	Examples      []string
+	LoginRequired bool
+	// Role is the least role a logged in user needs, if any.
+	Role string
+
+	// Hidden commands are left out of help, suggestions and completion.
+	Hidden bool
+	// RawArgs commands receive cmd.Args unparsed.
+	RawArgs bool
+}
+
+func (c *Commands) HandlerHelp(s *State, cmd Command) error {
+	if cmd.Options.IsSet("command") {
+		name := cmd.Options.String("command")
+		if _, ok := c.FunctionMap[name]; !ok {
+			return c.unknownCommandError(name)
+		}
+		c.printCommandHelp(s.Out, name)
+		return nil
+	}
+
+	c.PrintUsage(s.Out)
+	return nil
+}
+
+// PrintUsage lists every registered command with its synopsis.
+func (c *Commands) PrintUsage(out *output.Printer) {
+	out.Printf("%s gator <command> [arguments]\n\n", out.Style("Usage:", output.Bold))
+
+	table := output.NewTable("COMMAND", "ARGUMENTS", "DESCRIPTION").
+		SetStyle(0, output.Cyan).
+		SetStyle(1, output.Dim)
+	for _, name := range c.names() {
+		info := c.InfoMap[name]
+		description := info.Description
+		if info.LoginRequired {
+			description += " *"
+		}
+		if info.Role != "" && info.Role != RoleReadOnly {
+			description += " (" + info.Role + ")"
+		}
+		table.Row(name, info.Synopsis(), description)
+	}
+	out.Table(table)
+
+	out.Println()
+	out.Println("* requires a logged in user, (member) or (admin) one with at least that role")
+	out.Println()
+	out.Println("Run \"gator help <command>\" or \"gator <command> --help\" for details.")
+}
+
+func (c *Commands) printCommandHelp(out *output.Printer, name string) {
+	info := c.InfoMap[name]
+
+	out.Printf("%s gator %s\n", out.Style("Usage:", output.Bold), strings.TrimSpace(name+" "+info.Synopsis()))
+	out.Println()
+
+	if info.Description != "" {
+		out.Println(info.Description)
+	}
+	if info.Role != "" && info.Role != RoleReadOnly {
+		out.Printf("Requires a logged in user with the %s role or higher.\n", info.Role)
+	} else if info.LoginRequired {
+		out.Println("Requires a logged in user.")
+	}
+
+	if len(info.Flags) > 0 {
+		out.Println()
+		out.Println(out.Style("Flags:", output.Bold))
+
+		table := output.NewTable("FLAG", "TYPE", "DEFAULT", "DESCRIPTION")
+		for _, flag := range info.Flags {
+			names := "--" + flag.Name
+			if flag.Short != "" {
+				names = "-" + flag.Short + ", " + names
+			}
+			table.Row(names, flag.Kind.String(), flag.Default, flag.Usage)
+		}
+		out.Table(table)
+	}
+
+	if len(info.Examples) > 0 {
+		out.Println()
+		out.Println(out.Style("Examples:", output.Bold))
+		for _, example := range info.Examples {
+			out.Printf("  %s\n", example)
+		}
+	}
+}
+
+// names returns the visible command names in order.
+func (c *Commands) names() []string {
+	names := make([]string, 0, len(c.FunctionMap))
+	for name := range c.FunctionMap {
+		if c.InfoMap[name].Hidden {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *Commands) unknownCommandError(name string) error {
+	suggestions := c.suggest(name)
+	if len(suggestions) == 0 {
+		return fmt.Errorf("error: \"%s\" is not a command, run \"gator help\" for a list of commands", name)
+	}
+
+	quoted := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		quoted[i] = fmt.Sprintf("\"%s\"", suggestion)
+	}
+
+	return fmt.Errorf("error: \"%s\" is not a command. Did you mean %s?", name, strings.Join(quoted, " or "))
+}
+
+// suggest returns the registered commands closest to name, for "did you
+// mean" hints on typos.
+func (c *Commands) suggest(name string) []string {
+	best := maxSuggestionDistance + 1
+	suggestions := []string{}
+
+	for _, candidate := range c.names() {
+		distance := editDistance(strings.ToLower(name), candidate)
+		if strings.HasPrefix(candidate, strings.ToLower(name)) && len(name) >= 3 {
+			distance = min(distance, 1)
+		}
+
+		switch {
+		case distance < best:
+			best = distance
+			suggestions = []string{candidate}
+		case distance == best:
+			suggestions = append(suggestions, candidate)
+		}
+	}
+
+	return suggestions
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func wantsHelp(args []string) bool {
+	for _, arg := range args {
+		if arg == "--help" || arg == "-h" {
+			return true
+		}
+	}
+	return false
+}
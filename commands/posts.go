@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// shortPostIDLen is the number of leading UUID characters browse prints.
+const shortPostIDLen = 8
+
+func shortPostID(id uuid.UUID) string {
+	return id.String()[:shortPostIDLen]
+}
+
+func postTitle(title sql.NullString) string {
+	if !title.Valid || title.String == "" {
+		return "(untitled)"
+	}
+	return title.String
+}
+
+// resolvePost looks up a post either by its 1-based position in the default
+// browse listing, which leaves out muted posts, or by a (possibly shortened)
+// post id. Only posts from feeds user follows are found.
+func resolvePost(s *State, user database.User, ref string) (database.Post, error) {
+	if index, err := strconv.Atoi(ref); err == nil && len(ref) < shortPostIDLen {
+		if index < 1 {
+			return database.Post{}, fmt.Errorf("error: post index must be at least 1")
+		}
+
+		posts, _, err := getVisiblePosts(s, user, index)
+		if err != nil {
+			return database.Post{}, fmt.Errorf("unexpected error occurred in resolvePost: %v", err)
+		}
+
+		if len(posts) < index {
+			return database.Post{}, fmt.Errorf("there is no post at index %d", index)
+		}
+
+		post := posts[index-1]
+		return database.Post{
+			ID:          post.ID,
+			CreatedAt:   post.CreatedAt,
+			UpdatedAt:   post.UpdatedAt,
+			Title:       post.Title,
+			Url:         post.Url,
+			Description: post.Description,
+			PublishedAt: post.PublishedAt,
+			FeedID:      post.FeedID,
+			SeqID:       post.SeqID,
+			Author:      post.Author,
+			Categories:  post.Categories,
+			FetchedAt:   post.FetchedAt,
+		}, nil
+	}
+
+	prefix := strings.ToLower(ref)
+	if strings.Trim(prefix, "0123456789abcdef-") != "" {
+		return database.Post{}, fmt.Errorf("error: %s is neither a post index nor a post id", ref)
+	}
+
+	getPostsByIDPrefixParams := database.GetPostsByIDPrefixParams{
+		UserID: user.ID,
+		Prefix: prefix,
+	}
+
+	posts, err := s.Db.GetPostsByIDPrefix(context.Background(), getPostsByIDPrefixParams)
+	if err != nil {
+		return database.Post{}, fmt.Errorf("unexpected error occurred in resolvePost: %v", err)
+	}
+
+	switch len(posts) {
+	case 0:
+		return database.Post{}, fmt.Errorf("post %s does not exist", ref)
+	case 1:
+		return posts[0], nil
+	default:
+		return database.Post{}, fmt.Errorf("post id %s is ambiguous, use more characters", ref)
+	}
+}
@@ -0,0 +1,417 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/Cmolloy36/gator/internal/output"
+	"github.com/google/uuid"
+)
+
+const (
+	publishDefaultEntries = 50
+	publishMaxEntries     = 500
+	publishGenerator      = "gator"
+)
+
+// timelineFilter narrows a user's timeline to posts with a tag (one of
+// the categories given by the feed) or containing a keyword.
+type timelineFilter struct {
+	tag     string
+	keyword string
+}
+
+func (f timelineFilter) String() string {
+	switch {
+	case f.tag != "" && f.keyword != "":
+		return fmt.Sprintf("tagged %s, matching %s", f.tag, f.keyword)
+	case f.tag != "":
+		return fmt.Sprintf("tagged %s", f.tag)
+	case f.keyword != "":
+		return fmt.Sprintf("matching %s", f.keyword)
+	}
+	return ""
+}
+
+// fetchWindow narrows a timeline to the posts agg stored from since up to
+// but not including until. Zero times leave that end open.
+type fetchWindow struct {
+	since time.Time
+	until time.Time
+}
+
+// getTimelinePosts returns up to limit posts of the user's timeline, newest
+// first, leaving out posts muted by the user's filters. It also returns the
+// number of posts hidden.
+func getTimelinePosts(ctx context.Context, s *State, user database.User, filter timelineFilter, window fetchWindow, limit int) ([]database.GetTimelinePostsRow, int, error) {
+	filters, err := loadMuteFilters(ctx, s, user)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	fetch := func(n int) ([]database.GetTimelinePostsRow, error) {
+		getTimelinePostsParams := database.GetTimelinePostsParams{
+			UserID:       user.ID,
+			Tag:          sql.NullString{String: filter.tag, Valid: filter.tag != ""},
+			Keyword:      sql.NullString{String: filter.keyword, Valid: filter.keyword != ""},
+			FetchedSince: sql.NullTime{Time: window.since, Valid: !window.since.IsZero()},
+			FetchedUntil: sql.NullTime{Time: window.until, Valid: !window.until.IsZero()},
+			PageLimit:    int32(n),
+		}
+		return s.Db.GetTimelinePosts(ctx, getTimelinePostsParams)
+	}
+
+	return fetchVisible(limit, fetch, func(post database.GetTimelinePostsRow) bool {
+		return filters.hides(post.Title.String, post.Author.String, post.Categories, post.Url.String)
+	})
+}
+
+// timelineFeed is a user's timeline ready to be written as Atom or RSS.
+type timelineFeed struct {
+	user   database.User
+	filter timelineFilter
+	title  string
+	link   string
+	posts  []database.GetTimelinePostsRow
+}
+
+func newTimelineFeed(user database.User, filter timelineFilter, posts []database.GetTimelinePostsRow) timelineFeed {
+	title := fmt.Sprintf("%s's gator timeline", user.Name)
+	if filter != (timelineFilter{}) {
+		title += fmt.Sprintf(" (%s)", filter)
+	}
+	return timelineFeed{user: user, filter: filter, title: title, posts: posts}
+}
+
+// id identifies the feed: the user's id for the whole timeline, and a
+// name-based UUID of it and the filter otherwise, so each filtered feed
+// keeps its own stable id.
+func (f timelineFeed) id() uuid.UUID {
+	if f.filter == (timelineFilter{}) {
+		return f.user.ID
+	}
+	name := url.Values{"tag": {f.filter.tag}, "keyword": {f.filter.keyword}}.Encode()
+	return uuid.NewSHA1(f.user.ID, []byte(name))
+}
+
+// updated is the time of the newest post, or now for an empty feed.
+func (f timelineFeed) updated() time.Time {
+	if len(f.posts) == 0 {
+		return time.Now()
+	}
+	return f.posts[0].PublishedAt
+}
+
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Author    atomPerson  `xml:"author"`
+	Links     []atomLink  `xml:"link"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomSource struct {
+	ID    string     `xml:"id"`
+	Title string     `xml:"title"`
+	Links []atomLink `xml:"link"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Source     atomSource     `xml:"source"`
+}
+
+func (f timelineFeed) atom() atomFeed {
+	feed := atomFeed{
+		ID:        "urn:uuid:" + f.id().String(),
+		Title:     f.title,
+		Updated:   f.updated().UTC().Format(time.RFC3339),
+		Author:    atomPerson{Name: f.user.Name},
+		Generator: publishGenerator,
+	}
+	if f.link != "" {
+		feed.Links = []atomLink{{Href: f.link, Rel: "self", Type: "application/atom+xml"}}
+	}
+
+	for _, post := range f.posts {
+		entry := atomEntry{
+			ID:        "urn:uuid:" + post.ID.String(),
+			Title:     postTitle(post.Title),
+			Updated:   post.UpdatedAt.UTC().Format(time.RFC3339),
+			Published: post.PublishedAt.UTC().Format(time.RFC3339),
+			Source: atomSource{
+				ID:    post.FeedUrl,
+				Title: post.FeedName,
+				Links: []atomLink{{Href: post.FeedUrl, Rel: "self"}},
+			},
+		}
+		if post.Author.Valid {
+			entry.Author = &atomPerson{Name: post.Author.String}
+		}
+		if post.Url.Valid && post.Url.String != "" {
+			entry.Links = []atomLink{{Href: post.Url.String, Rel: "alternate"}}
+		}
+		for _, category := range post.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		// An entry without an alternate link needs content or a summary
+		if post.Description.Valid && post.Description.String != "" {
+			entry.Summary = &atomText{Type: "html", Body: post.Description.String}
+		} else if entry.Links == nil {
+			entry.Summary = &atomText{Type: "text", Body: entry.Title}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return feed
+}
+
+type rssOut struct {
+	XMLName xml.Name      `xml:"rss"`
+	Version string        `xml:"version,attr"`
+	Channel rssOutChannel `xml:"channel"`
+}
+
+type rssOutChannel struct {
+	Title         string       `xml:"title"`
+	Link          string       `xml:"link"`
+	Description   string       `xml:"description"`
+	LastBuildDate string       `xml:"lastBuildDate"`
+	Generator     string       `xml:"generator"`
+	Items         []rssOutItem `xml:"item"`
+}
+
+type rssOutGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssOutSource struct {
+	Url  string `xml:"url,attr"`
+	Name string `xml:",chardata"`
+}
+
+type rssOutItem struct {
+	Title       string       `xml:"title"`
+	Link        string       `xml:"link,omitempty"`
+	Description string       `xml:"description,omitempty"`
+	Creator     string       `xml:"http://purl.org/dc/elements/1.1/ creator,omitempty"`
+	Categories  []string     `xml:"category"`
+	GUID        rssOutGUID   `xml:"guid"`
+	PubDate     string       `xml:"pubDate"`
+	Source      rssOutSource `xml:"source"`
+}
+
+func (f timelineFeed) rss() rssOut {
+	channel := rssOutChannel{
+		Title:         f.title,
+		Link:          f.link,
+		Description:   fmt.Sprintf("Posts from the feeds %s follows", f.user.Name),
+		LastBuildDate: f.updated().Format(time.RFC1123Z),
+		Generator:     publishGenerator,
+	}
+
+	for _, post := range f.posts {
+		channel.Items = append(channel.Items, rssOutItem{
+			Title:       postTitle(post.Title),
+			Link:        post.Url.String,
+			Description: post.Description.String,
+			Creator:     post.Author.String,
+			Categories:  post.Categories,
+			GUID:        rssOutGUID{IsPermaLink: "false", Value: "urn:uuid:" + post.ID.String()},
+			PubDate:     post.PublishedAt.Format(time.RFC1123Z),
+			Source:      rssOutSource{Url: post.FeedUrl, Name: post.FeedName},
+		})
+	}
+
+	return rssOut{Version: "2.0", Channel: channel}
+}
+
+// write encodes the feed as "atom" or "rss".
+func (f timelineFeed) write(w io.Writer, kind string) error {
+	var doc any = f.atom()
+	if kind == "rss" {
+		doc = f.rss()
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func HandlerPublish(s *State, cmd Command, user database.User) error {
+	kind := cmd.Options.String("kind")
+	link := cmd.Options.String("link")
+	if kind == "rss" && link == "" {
+		return fmt.Errorf("error: RSS feeds need a --link, the URL the feed will be published at")
+	}
+
+	limit := cmd.Options.Int("limit")
+	if limit < 1 || limit > publishMaxEntries {
+		return fmt.Errorf("error: --limit must be between 1 and %d", publishMaxEntries)
+	}
+
+	filter := timelineFilter{
+		tag:     cmd.Options.String("tag"),
+		keyword: cmd.Options.String("keyword"),
+	}
+
+	posts, hidden, err := getTimelinePosts(context.Background(), s, user, filter, fetchWindow{}, limit)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerPublish: %v", err)
+	}
+
+	feed := newTimelineFeed(user, filter, posts)
+	feed.link = link
+	if title := cmd.Options.String("title"); title != "" {
+		feed.title = title
+	}
+
+	path := cmd.Options.String("file")
+	if path == "" {
+		if err := feed.write(s.Out.Writer(), kind); err != nil {
+			return err
+		}
+		// Warnings go to stderr, so this stays out of the feed
+		if hidden > 0 {
+			s.Out.Warnf("%s", hiddenNotice(hidden))
+		}
+		return nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error: unable to create %s: %v", path, err)
+	}
+	defer file.Close()
+
+	if err := feed.write(file, kind); err != nil {
+		return fmt.Errorf("error: unable to write %s: %v", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error: unable to write %s: %v", path, err)
+	}
+
+	s.Out.Successf("Wrote %d post(s) to %s", len(posts), path)
+	if hidden > 0 {
+		s.Out.Println(s.Out.Style(hiddenNotice(hidden), output.Dim))
+	}
+
+	return nil
+}
+
+// handleTimelineFeed serves the user's timeline as a feed. Feed readers
+// rarely send headers, so a feed key may also be given as ?key=. Full API
+// keys are not accepted there, since they would end up in URLs.
+func (a *apiServer) handleTimelineFeed(kind string) http.HandlerFunc {
+	serve := func(w http.ResponseWriter, r *http.Request, user database.User) {
+		query := r.URL.Query()
+
+		limit, err := queryInt(r, "limit", publishDefaultEntries)
+		if err != nil {
+			respondWithErr(w, err)
+			return
+		}
+		if limit < 1 || limit > publishMaxEntries {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", publishMaxEntries))
+			return
+		}
+
+		filter := timelineFilter{
+			tag:     query.Get("tag"),
+			keyword: query.Get("keyword"),
+		}
+
+		posts, hidden, err := getTimelinePosts(r.Context(), a.s, user, filter, fetchWindow{}, limit)
+		if err != nil {
+			respondWithErr(w, err)
+			return
+		}
+
+		// Link to the feed itself, without the key
+		query.Del("key")
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		link := fmt.Sprintf("%s://%s%s", scheme, r.Host, r.URL.Path)
+		if len(query) > 0 {
+			link += "?" + query.Encode()
+		}
+
+		feed := newTimelineFeed(user, filter, posts)
+		feed.link = link
+
+		w.Header().Set("Content-Type", fmt.Sprintf("application/%s+xml; charset=utf-8", kind))
+		w.Header().Set("X-Gator-Hidden", strconv.Itoa(hidden))
+		feed.write(w, kind)
+	}
+	serveAuthed := a.authed(RoleReadOnly, serve)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("key")
+		if key == "" || r.Header.Get("Authorization") != "" {
+			serveAuthed(w, r)
+			return
+		}
+
+		user, err := userByAPIKey(r.Context(), a.s, key, apiKeyScopeFeed)
+		if err != nil {
+			var apiErr apiError
+			if errors.As(err, &apiErr) && apiErr.status == http.StatusUnauthorized {
+				err = apiError{http.StatusUnauthorized, "invalid feed key, create one with \"gator apikey create <name> --feed\""}
+			}
+			respondWithErr(w, err)
+			return
+		}
+		serve(w, r, user)
+	}
+}
@@ -0,0 +1,283 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/database"
+)
+
+// Records are the stable schema emitted by listing commands when a
+// structured --output format is selected. Field tags name the JSON keys,
+// CSV columns and YAML keys.
+
+type userRecord struct {
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	Current   bool      `json:"current"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type feedRecord struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Url           string     `json:"url"`
+	Owner         string     `json:"owner"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+}
+
+// apiPostRecord is a post as served by the HTTP API, with the requesting
+// user's read state.
+type apiPostRecord struct {
+	ID          string     `json:"id"`
+	FeedID      string     `json:"feed_id"`
+	FeedName    string     `json:"feed_name"`
+	Title       string     `json:"title"`
+	Url         string     `json:"url"`
+	Description string     `json:"description"`
+	PublishedAt time.Time  `json:"published_at"`
+	ReadAt      *time.Time `json:"read_at"`
+	Starred     bool       `json:"starred"`
+}
+
+type apiKeyRecord struct {
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scope      string     `json:"scope"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+type alertRuleRecord struct {
+	ID        string    `json:"id"`
+	Pattern   string    `json:"pattern"`
+	Regex     bool      `json:"regex"`
+	Feed      string    `json:"feed"`
+	Matches   int64     `json:"matches"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type alertRecord struct {
+	ID              string    `json:"id"`
+	Pattern         string    `json:"pattern"`
+	Regex           bool      `json:"regex"`
+	FeedName        string    `json:"feed_name"`
+	PostID          string    `json:"post_id"`
+	PostTitle       string    `json:"post_title"`
+	PostUrl         string    `json:"post_url"`
+	PostPublishedAt time.Time `json:"post_published_at"`
+	MatchedAt       time.Time `json:"matched_at"`
+}
+
+type filterRecord struct {
+	ID        string    `json:"id"`
+	Field     string    `json:"field"`
+	Pattern   string    `json:"pattern"`
+	Regex     bool      `json:"regex"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type webhookRecord struct {
+	ID        string    `json:"id"`
+	Url       string    `json:"url"`
+	Feed      string    `json:"feed"`
+	Keyword   string    `json:"keyword"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type webhookDeliveryRecord struct {
+	ID             string     `json:"id"`
+	WebhookID      string     `json:"webhook_id"`
+	WebhookUrl     string     `json:"webhook_url"`
+	PostID         string     `json:"post_id"`
+	PostTitle      string     `json:"post_title"`
+	Status         string     `json:"status"`
+	Attempts       int32      `json:"attempts"`
+	ResponseStatus *int32     `json:"response_status"`
+	Error          string     `json:"error"`
+	CreatedAt      time.Time  `json:"created_at"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+}
+
+type feedInfoRecord struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Url           string     `json:"url"`
+	Owner         string     `json:"owner"`
+	Followers     int64      `json:"followers"`
+	Posts         int64      `json:"posts"`
+	PostsPerWeek  float64    `json:"posts_per_week"`
+	NewestPostAt  *time.Time `json:"newest_post_at"`
+	OldestPostAt  *time.Time `json:"oldest_post_at"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	LastError     string     `json:"last_error"`
+	LastErrorAt   *time.Time `json:"last_error_at"`
+	Fetches       int64      `json:"fetches"`
+	FailedFetches int64      `json:"failed_fetches"`
+	AvgFetchMs    float64    `json:"avg_fetch_ms"`
+}
+
+type followRecord struct {
+	FeedID     string    `json:"feed_id"`
+	FeedName   string    `json:"feed_name"`
+	FeedUrl    string    `json:"feed_url"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+type postRecord struct {
+	ID          string    `json:"id"`
+	ShortID     string    `json:"short_id"`
+	Index       int       `json:"index"`
+	FeedID      string    `json:"feed_id"`
+	FeedName    string    `json:"feed_name"`
+	Title       string    `json:"title"`
+	Url         string    `json:"url"`
+	Description string    `json:"description"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+func newUserRecord(user database.User, currentUserName string) userRecord {
+	return userRecord{
+		Name:      user.Name,
+		Role:      user.Role,
+		Current:   user.Name == currentUserName,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+func newFeedRecord(feed database.Feed, owner string) feedRecord {
+	record := feedRecord{
+		ID:        feed.ID.String(),
+		Name:      feed.Name,
+		Url:       feed.Url,
+		Owner:     owner,
+		CreatedAt: feed.CreatedAt,
+	}
+	if feed.LastFetchedAt.Valid {
+		record.LastFetchedAt = &feed.LastFetchedAt.Time
+	}
+	return record
+}
+
+func newFollowRecord(follow database.GetFeedFollowsForUserRow) followRecord {
+	return followRecord{
+		FeedID:     follow.FeedID.String(),
+		FeedName:   follow.FeedName,
+		FeedUrl:    follow.FeedUrl,
+		FollowedAt: follow.CreatedAt,
+	}
+}
+
+func newPostRecord(post database.GetPostsForUserRow, index int) postRecord {
+	return postRecord{
+		ID:          post.ID.String(),
+		ShortID:     shortPostID(post.ID),
+		Index:       index,
+		FeedID:      post.FeedID.String(),
+		FeedName:    post.FeedName,
+		Title:       post.Title.String,
+		Url:         post.Url.String,
+		Description: post.Description.String,
+		PublishedAt: post.PublishedAt,
+	}
+}
+
+func newAPIPostRecord(post database.GetPostWithStateRow) apiPostRecord {
+	record := apiPostRecord{
+		ID:          post.ID.String(),
+		FeedID:      post.FeedID.String(),
+		FeedName:    post.FeedName,
+		Title:       post.Title.String,
+		Url:         post.Url.String,
+		Description: post.Description.String,
+		PublishedAt: post.PublishedAt,
+		Starred:     post.Starred,
+	}
+	if post.ReadAt.Valid {
+		record.ReadAt = &post.ReadAt.Time
+	}
+	return record
+}
+
+func newAPIKeyRecord(key database.ApiKey) apiKeyRecord {
+	record := apiKeyRecord{
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scope:     key.Scope,
+		CreatedAt: key.CreatedAt,
+	}
+	if key.LastUsedAt.Valid {
+		record.LastUsedAt = &key.LastUsedAt.Time
+	}
+	return record
+}
+
+func newAlertRuleRecord(rule database.GetAlertRulesForUserRow) alertRuleRecord {
+	return alertRuleRecord{
+		ID:        rule.ID.String(),
+		Pattern:   rule.Pattern,
+		Regex:     rule.IsRegex,
+		Feed:      rule.FeedName.String,
+		Matches:   rule.Matches,
+		CreatedAt: rule.CreatedAt,
+	}
+}
+
+func newAlertRecord(alert database.GetAlertsForUserRow) alertRecord {
+	return alertRecord{
+		ID:              alert.ID.String(),
+		Pattern:         alert.Pattern,
+		Regex:           alert.IsRegex,
+		FeedName:        alert.FeedName,
+		PostID:          alert.PostID.String(),
+		PostTitle:       alert.PostTitle.String,
+		PostUrl:         alert.PostUrl.String,
+		PostPublishedAt: alert.PostPublishedAt,
+		MatchedAt:       alert.CreatedAt,
+	}
+}
+
+func newFilterRecord(filter database.Filter) filterRecord {
+	return filterRecord{
+		ID:        filter.ID.String(),
+		Field:     filter.Field,
+		Pattern:   filter.Pattern,
+		Regex:     filter.IsRegex,
+		CreatedAt: filter.CreatedAt,
+	}
+}
+
+func newWebhookRecord(webhook database.GetWebhooksForUserRow) webhookRecord {
+	return webhookRecord{
+		ID:        webhook.ID.String(),
+		Url:       webhook.Url,
+		Feed:      webhook.FeedName.String,
+		Keyword:   webhook.Keyword.String,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func newWebhookDeliveryRecord(delivery database.GetWebhookDeliveriesForUserRow) webhookDeliveryRecord {
+	record := webhookDeliveryRecord{
+		ID:         delivery.ID.String(),
+		WebhookID:  delivery.WebhookID.String(),
+		WebhookUrl: delivery.WebhookUrl,
+		PostID:     delivery.PostID.String(),
+		PostTitle:  delivery.PostTitle.String,
+		Status:     delivery.Status,
+		Attempts:   delivery.Attempts,
+		Error:      delivery.Error.String,
+		CreatedAt:  delivery.CreatedAt,
+	}
+	if delivery.ResponseStatus.Valid {
+		record.ResponseStatus = &delivery.ResponseStatus.Int32
+	}
+	if delivery.Status == deliveryPending && delivery.NextAttemptAt.Valid {
+		record.NextAttemptAt = &delivery.NextAttemptAt.Time
+	}
+	if delivery.DeliveredAt.Valid {
+		record.DeliveredAt = &delivery.DeliveredAt.Time
+	}
+	return record
+}
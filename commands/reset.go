@@ -0,0 +1,251 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Cmolloy36/gator/internal/database"
+)
+
+// resetPlan describes what a reset will delete so it can be shown, and
+// confirmed, before apply runs.
+type resetPlan struct {
+	target string
+	counts []resetCount
+	apply  func(ctx context.Context) error
+	// done replaces the default "Reset <target>" success message.
+	done string
+}
+
+type resetCount struct {
+	label string
+	count int64
+}
+
+func HandlerReset(s *State, cmd Command, admin database.User) error {
+	scopes := 0
+	for _, set := range []bool{cmd.Options.Bool("posts"), cmd.Options.IsSet("feed"), cmd.Options.IsSet("user")} {
+		if set {
+			scopes++
+		}
+	}
+	if scopes > 1 {
+		return fmt.Errorf("error: only one of --posts, --feed and --user can be given to \"reset\"")
+	}
+
+	var plan resetPlan
+	var err error
+
+	switch {
+	case cmd.Options.Bool("posts"):
+		plan, err = planResetPosts(s)
+	case cmd.Options.IsSet("feed"):
+		plan, err = planResetFeed(s, cmd.Options.String("feed"))
+	case cmd.Options.IsSet("user"):
+		plan, err = planResetUser(s, cmd.Options.String("user"))
+	default:
+		plan, err = planResetAll(s)
+	}
+	if err != nil {
+		return err
+	}
+
+	return runResetPlan(s, cmd, plan)
+}
+
+// runResetPlan prints plan, then applies it unless --dry-run was given or
+// the user declines the confirmation prompt.
+func runResetPlan(s *State, cmd Command, plan resetPlan) error {
+	pairs := []string{}
+	for _, c := range plan.counts {
+		pairs = append(pairs, c.label, strconv.FormatInt(c.count, 10))
+	}
+
+	if cmd.Options.Bool("dry-run") {
+		s.Out.Printf("Resetting %s would delete:\n", plan.target)
+		s.Out.Fields(pairs...)
+		s.Out.Println("Dry run, nothing was deleted.")
+		return nil
+	}
+
+	s.Out.Printf("Resetting %s deletes:\n", plan.target)
+	s.Out.Fields(pairs...)
+
+	if !cmd.Options.Bool("yes") {
+		ok, err := confirm(s, "Continue?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			s.Out.Warnf("Aborted, nothing was deleted")
+			return nil
+		}
+	}
+
+	err := plan.apply(context.Background())
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred: %v", err)
+	}
+
+	if plan.done != "" {
+		s.Out.Successf("%s", plan.done)
+	} else {
+		s.Out.Successf("Reset %s", plan.target)
+	}
+
+	return nil
+}
+
+func planResetAll(s *State) (resetPlan, error) {
+	ctx := context.Background()
+
+	numUsers, err := s.Db.GetNumRecords(ctx)
+	if err != nil {
+		return resetPlan{}, fmt.Errorf("unexpected error occurred: %v", err)
+	}
+	numFeeds, err := s.Db.CountFeeds(ctx)
+	if err != nil {
+		return resetPlan{}, fmt.Errorf("unexpected error occurred: %v", err)
+	}
+	numFollows, err := s.Db.CountFeedFollows(ctx)
+	if err != nil {
+		return resetPlan{}, fmt.Errorf("unexpected error occurred: %v", err)
+	}
+	numPosts, err := s.Db.CountPosts(ctx)
+	if err != nil {
+		return resetPlan{}, fmt.Errorf("unexpected error occurred: %v", err)
+	}
+
+	return resetPlan{
+		target: "the whole database",
+		counts: []resetCount{
+			{"Users", numUsers},
+			{"Feeds", numFeeds},
+			{"Follows", numFollows},
+			{"Posts", numPosts},
+		},
+		apply: func(ctx context.Context) error {
+			// Feeds, follows and posts are removed by cascade
+			if err := s.Db.ResetUsers(ctx); err != nil {
+				return err
+			}
+			return s.ConfigStruct.Logout()
+		},
+	}, nil
+}
+
+func planResetPosts(s *State) (resetPlan, error) {
+	numPosts, err := s.Db.CountPosts(context.Background())
+	if err != nil {
+		return resetPlan{}, fmt.Errorf("unexpected error occurred: %v", err)
+	}
+
+	return resetPlan{
+		target: "all posts",
+		counts: []resetCount{{"Posts", numPosts}},
+		apply: func(ctx context.Context) error {
+			_, err := s.Db.DeleteAllPosts(ctx)
+			return err
+		},
+	}, nil
+}
+
+// planResetFeed clears the posts and fetch history of one feed so agg
+// fetches it from scratch. The feed and its follows are kept.
+func planResetFeed(s *State, ref string) (resetPlan, error) {
+	ctx := context.Background()
+
+	feed, err := resolveFeed(s, ref)
+	if err != nil {
+		return resetPlan{}, err
+	}
+
+	numPosts, err := s.Db.CountPostsForFeed(ctx, feed.ID)
+	if err != nil {
+		return resetPlan{}, fmt.Errorf("unexpected error occurred: %v", err)
+	}
+	getFeedFetchStatsParams := database.GetFeedFetchStatsParams{
+		FeedID: feed.ID,
+		Limit:  feedFetchHistory,
+	}
+
+	fetchStats, err := s.Db.GetFeedFetchStats(ctx, getFeedFetchStatsParams)
+	if err != nil {
+		return resetPlan{}, fmt.Errorf("unexpected error occurred: %v", err)
+	}
+
+	return resetPlan{
+		target: fmt.Sprintf("feed \"%s\"", feed.Name),
+		counts: []resetCount{
+			{"Posts", numPosts},
+			{"Fetch records", fetchStats.Fetches},
+		},
+		apply: func(ctx context.Context) error {
+			if _, err := s.Db.DeletePostsForFeed(ctx, feed.ID); err != nil {
+				return err
+			}
+			if err := s.Db.DeleteFeedFetchesForFeed(ctx, feed.ID); err != nil {
+				return err
+			}
+
+			markFeedFetchedParams := database.MarkFeedFetchedParams{
+				ID:            feed.ID,
+				LastFetchedAt: sql.NullTime{},
+			}
+			return s.Db.MarkFeedFetched(ctx, markFeedFetchedParams)
+		},
+	}, nil
+}
+
+// planResetUser deletes one user together with the feeds they added, those
+// feeds' posts and every follow of the user or of their feeds.
+func planResetUser(s *State, name string) (resetPlan, error) {
+	ctx := context.Background()
+
+	user, err := s.Db.GetUser(ctx, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return resetPlan{}, fmt.Errorf("%s does not exist", name)
+		}
+		return resetPlan{}, fmt.Errorf("unexpected error occurred: %v", err)
+	}
+
+	if err := checkNotLastAdmin(s, user); err != nil {
+		return resetPlan{}, err
+	}
+
+	numFeeds, err := s.Db.CountFeedsForUser(ctx, user.ID)
+	if err != nil {
+		return resetPlan{}, fmt.Errorf("unexpected error occurred: %v", err)
+	}
+	numFollows, err := s.Db.CountFeedFollowsAffectedByUser(ctx, user.ID)
+	if err != nil {
+		return resetPlan{}, fmt.Errorf("unexpected error occurred: %v", err)
+	}
+	numPosts, err := s.Db.CountPostsForUserFeeds(ctx, user.ID)
+	if err != nil {
+		return resetPlan{}, fmt.Errorf("unexpected error occurred: %v", err)
+	}
+
+	return resetPlan{
+		target: fmt.Sprintf("user %s", user.Name),
+		counts: []resetCount{
+			{"Users", 1},
+			{"Feeds", numFeeds},
+			{"Follows", numFollows},
+			{"Posts", numPosts},
+		},
+		apply: func(ctx context.Context) error {
+			if err := s.Db.DeleteUser(ctx, user.ID); err != nil {
+				return err
+			}
+			if s.ConfigStruct.Current_user_name == user.Name {
+				return s.ConfigStruct.Logout()
+			}
+			return nil
+		},
+	}, nil
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/database"
+)
+
+// Roles, from least to most privileged. Read-only users may browse and keep
+// their own read state, members may also add and follow feeds, and admins
+// may manage users and reset the database.
+const (
+	RoleReadOnly = "read-only"
+	RoleMember   = "member"
+	RoleAdmin    = "admin"
+)
+
+var roleRanks = map[string]int{
+	RoleReadOnly: 0,
+	RoleMember:   1,
+	RoleAdmin:    2,
+}
+
+// Roles lists the valid roles from least to most privileged.
+var Roles = []string{RoleReadOnly, RoleMember, RoleAdmin}
+
+func hasRole(user database.User, role string) bool {
+	return roleRanks[user.Role] >= roleRanks[role]
+}
+
+// MiddlewareRole rejects users whose role is below role. It runs inside
+// MiddlewareLoggedIn, which supplies the user.
+func MiddlewareRole(role string, handler func(s *State, cmd Command, user database.User) error) func(*State, Command, database.User) error {
+	return func(s *State, cmd Command, user database.User) error {
+		if !hasRole(user, role) {
+			return fmt.Errorf("error: \"%s\" requires the %s role, %s is %s", cmd.Name, role, user.Name, user.Role)
+		}
+		return handler(s, cmd, user)
+	}
+}
+
+// RegisterWithRole registers a handler that runs as the logged in user and
+// only if their role is at least role.
+func (c *Commands) RegisterWithRole(name string, info CommandInfo, role string, f func(*State, Command, database.User) error) {
+	info.Role = role
+	c.RegisterLoggedIn(name, info, MiddlewareRole(role, f))
+}
+
+func HandlerGrant(s *State, cmd Command, admin database.User) error {
+	user, err := getUserByName(s, cmd.Options.String("name"))
+	if err != nil {
+		return err
+	}
+
+	role := cmd.Options.String("role")
+	if user.Role == role {
+		return fmt.Errorf("%s already has the %s role", user.Name, role)
+	}
+
+	if err := checkNotLastAdmin(s, user); err != nil {
+		return err
+	}
+
+	return setUserRole(s, user, role)
+}
+
+// HandlerRevoke takes role away from a user, leaving them with the role
+// one step below it.
+func HandlerRevoke(s *State, cmd Command, admin database.User) error {
+	user, err := getUserByName(s, cmd.Options.String("name"))
+	if err != nil {
+		return err
+	}
+
+	role := cmd.Options.String("role")
+	if user.Role != role {
+		return fmt.Errorf("%s does not have the %s role (they are %s)", user.Name, role, user.Role)
+	}
+	if role == RoleReadOnly {
+		return fmt.Errorf("error: read-only is the lowest role, use \"gator deluser %s\" to remove the user", user.Name)
+	}
+
+	if role == RoleAdmin {
+		if err := checkNotLastAdmin(s, user); err != nil {
+			return err
+		}
+	}
+
+	return setUserRole(s, user, Roles[roleRanks[role]-1])
+}
+
+func setUserRole(s *State, user database.User, role string) error {
+	setUserRoleParams := database.SetUserRoleParams{
+		ID:        user.ID,
+		Role:      role,
+		UpdatedAt: time.Now(),
+	}
+
+	updated, err := s.Db.SetUserRole(context.Background(), setUserRoleParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in setUserRole: %v", err)
+	}
+
+	s.Out.Successf("%s is now %s (was %s)", updated.Name, updated.Role, user.Role)
+
+	return nil
+}
+
+// checkNotLastAdmin keeps the database from being left without an admin.
+func checkNotLastAdmin(s *State, user database.User) error {
+	if user.Role != RoleAdmin {
+		return nil
+	}
+
+	numAdmins, err := s.Db.CountAdmins(context.Background())
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in checkNotLastAdmin: %v", err)
+	}
+
+	if numAdmins <= 1 {
+		return fmt.Errorf("error: %s is the only admin, grant the admin role to another user first", user.Name)
+	}
+
+	return nil
+}
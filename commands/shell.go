@@ -0,0 +1,324 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"golang.org/x/term"
+)
+
+const (
+	shellHistoryFileName = ".gator_history"
+	shellHistoryLimit    = 1000
+)
+
+// HandlerShell runs commands read from a prompt until "exit" or EOF, reusing
+// s and its database connection for every command.
+func (c *Commands) HandlerShell(s *State, cmd Command) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return c.runShellScript(s)
+	}
+
+	history := loadShellHistory()
+
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "")
+	terminal.History = history
+	terminal.AutoCompleteCallback = c.shellAutoComplete(s)
+
+	s.Out.Println("gator interactive shell. Type \"help\" for commands, \"exit\" to quit.")
+
+	for {
+		terminal.SetPrompt(shellPrompt(s))
+
+		line, err := readShellLine(fd, terminal)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				s.Out.Println()
+				return nil
+			}
+			return fmt.Errorf("unexpected error occurred in HandlerShell: %v", err)
+		}
+
+		if done := c.runShellLine(s, line); done {
+			return nil
+		}
+	}
+}
+
+// readShellLine puts the terminal in raw mode only while a line is being
+// edited, so commands print to a normal terminal.
+func readShellLine(fd int, terminal *term.Terminal) (string, error) {
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(fd, oldState)
+
+	return terminal.ReadLine()
+}
+
+// runShellScript runs the lines of a script piped to stdin. Lines are read
+// through s.stdin, so a command that reads stdin itself, such as a password
+// prompt, gets the line that follows it.
+func (c *Commands) runShellScript(s *State) error {
+	for {
+		line, err := s.stdin().ReadString('\n')
+		if line != "" {
+			if done := c.runShellLine(s, strings.TrimRight(line, "\r\n")); done {
+				return nil
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unexpected error occurred in runShellScript: %v", err)
+		}
+	}
+}
+
+// runShellLine runs a single line and reports whether the shell should exit.
+func (c *Commands) runShellLine(s *State, line string) bool {
+	words, err := splitCommandLine(line)
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+
+	if len(words) == 0 {
+		return false
+	}
+
+	switch words[0] {
+	case "exit", "quit":
+		return true
+	case "shell":
+		fmt.Println("error: already in the gator shell")
+		return false
+	}
+
+	shellCmd := Command{
+		Name: words[0],
+		Args: words[1:],
+	}
+
+	if err := c.Run(s, shellCmd); err != nil {
+		fmt.Println(err)
+	}
+
+	return false
+}
+
+func shellPrompt(s *State) string {
+	if s.ConfigStruct.Current_user_name == "" {
+		return "gator> "
+	}
+	return fmt.Sprintf("gator (%s)> ", s.ConfigStruct.Current_user_name)
+}
+
+// shellAutoComplete completes the word before the cursor on Tab using the
+// same candidates as shell completion. A unique match is inserted; several
+// matches are extended to their common prefix.
+func (c *Commands) shellAutoComplete(s *State) func(string, int, rune) (string, int, bool) {
+	return func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+
+		before := line[:pos]
+		words := strings.Fields(before)
+		if len(words) == 0 || unicode.IsSpace(rune(before[len(before)-1])) {
+			words = append(words, "")
+		}
+
+		current := words[len(words)-1]
+		matches := []string{}
+		for _, candidate := range c.completionCandidates(s, words) {
+			if strings.HasPrefix(candidate, current) {
+				matches = append(matches, candidate)
+			}
+		}
+
+		if len(matches) == 0 {
+			return "", 0, false
+		}
+
+		completed := commonPrefix(matches)
+		if len(matches) == 1 {
+			completed += " "
+		}
+
+		newBefore := before[:len(before)-len(current)] + completed
+		return newBefore + line[pos:], len(newBefore), true
+	}
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// splitCommandLine splits line into words like a POSIX shell would for
+// simple input: whitespace separates words, single and double quotes group
+// them and a backslash escapes the next character outside single quotes.
+func splitCommandLine(line string) ([]string, error) {
+	words := []string{}
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("error: unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("error: trailing backslash")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// shellHistory keeps the most recent lines in memory and appends each new
+// line to ~/.gator_history so history survives between sessions. Once the
+// file holds shellHistoryLimit lines, it is rewritten with the lines kept
+// in memory instead, so it stops growing.
+type shellHistory struct {
+	entries []string
+	path    string
+	// fileLines is how many lines ~/.gator_history holds.
+	fileLines int
+}
+
+func loadShellHistory() *shellHistory {
+	h := &shellHistory{}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return h
+	}
+	h.path = filepath.Join(homeDir, shellHistoryFileName)
+
+	content, err := os.ReadFile(h.path)
+	if err != nil {
+		return h
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	h.fileLines = len(h.entries)
+	if len(h.entries) > shellHistoryLimit {
+		h.entries = h.entries[len(h.entries)-shellHistoryLimit:]
+	}
+
+	return h
+}
+
+func (h *shellHistory) Add(entry string) {
+	if strings.TrimSpace(entry) == "" {
+		return
+	}
+	if len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry {
+		return
+	}
+
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > shellHistoryLimit {
+		h.entries = h.entries[1:]
+	}
+
+	if h.path == "" {
+		return
+	}
+
+	if h.fileLines >= shellHistoryLimit {
+		h.rewrite()
+		return
+	}
+
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintln(f, entry); err == nil {
+		h.fileLines++
+	}
+}
+
+// rewrite replaces the history file with the entries kept in memory,
+// through a temporary file so a failed write keeps the old history.
+func (h *shellHistory) rewrite() {
+	tmp, err := os.CreateTemp(filepath.Dir(h.path), shellHistoryFileName+".*.tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(strings.Join(h.entries, "\n") + "\n")
+	if closeErr := tmp.Close(); err != nil || closeErr != nil {
+		return
+	}
+
+	if err := os.Rename(tmp.Name(), h.path); err == nil {
+		h.fileLines = len(h.entries)
+	}
+}
+
+func (h *shellHistory) Len() int {
+	return len(h.entries)
+}
+
+// At returns the idx-th most recent entry.
+func (h *shellHistory) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Cmolloy36/gator/internal/config"
+	"github.com/Cmolloy36/gator/internal/output"
+)
+
+// TestShellScriptStdin checks that a command in a piped script reads the
+// script's next line, as "passwd" does for the password.
+func TestShellScriptStdin(t *testing.T) {
+	passwords := []string{}
+	c := &Commands{
+		FunctionMap: map[string]func(*State, Command) error{},
+		InfoMap:     map[string]CommandInfo{},
+	}
+	c.Register("secret", CommandInfo{}, func(s *State, cmd Command) error {
+		password, err := promptPassword(s, "Password: ")
+		if err != nil {
+			return err
+		}
+		passwords = append(passwords, password)
+		return nil
+	})
+
+	s := &State{
+		ConfigStruct: &config.Config{},
+		Out:          output.New(io.Discard),
+		In:           bufio.NewReader(strings.NewReader("secret\ncorrect horse\n\nsecret\r\nbattery staple")),
+	}
+
+	if err := c.runShellScript(s); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"correct horse", "battery staple"}; !slices.Equal(passwords, want) {
+		t.Errorf("passwords read: %q, want %q", passwords, want)
+	}
+}
+
+// TestShellHistoryLimit checks that ~/.gator_history is cut back to
+// shellHistoryLimit lines instead of growing.
+func TestShellHistoryLimit(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path := filepath.Join(home, shellHistoryFileName)
+	lines := []string{}
+	for i := range shellHistoryLimit + 10 {
+		lines = append(lines, fmt.Sprintf("browse %d", i))
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	h := loadShellHistory()
+	h.Add("agg 1m")
+	h.Add("feeds")
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(saved) != shellHistoryLimit {
+		t.Errorf("history file holds %d lines, want %d", len(saved), shellHistoryLimit)
+	}
+	if saved[len(saved)-1] != "feeds" || saved[0] != "browse 12" {
+		t.Errorf("history file runs from %q to %q", saved[0], saved[len(saved)-1])
+	}
+	if h.Len() != shellHistoryLimit || h.At(0) != "feeds" {
+		t.Errorf("history holds %d entries, the latest %q", h.Len(), h.At(0))
+	}
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/Cmolloy36/gator/internal/output"
+	"github.com/Cmolloy36/gator/internal/site"
+)
+
+func HandlerSite(s *State, cmd Command, user database.User) error {
+	switch cmd.Options.String("action") {
+	case "build":
+		return buildSite(s, cmd, user)
+	}
+	return nil
+}
+
+func buildSite(s *State, cmd Command, user database.User) error {
+	outDir := cmd.Options.String("outdir")
+
+	limit := cmd.Options.Int("limit")
+	if limit < 1 {
+		return fmt.Errorf("error: --limit must be at least 1")
+	}
+
+	perPage := cmd.Options.Int("per-page")
+	if perPage < 1 {
+		return fmt.Errorf("error: --per-page must be at least 1")
+	}
+
+	filter := timelineFilter{
+		tag:     cmd.Options.String("tag"),
+		keyword: cmd.Options.String("keyword"),
+	}
+
+	posts, hidden, err := getTimelinePosts(context.Background(), s, user, filter, fetchWindow{}, limit)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in buildSite: %v", err)
+	}
+
+	title := cmd.Options.String("title")
+	if title == "" {
+		title = newTimelineFeed(user, filter, nil).title
+	}
+
+	timeline := &site.Site{
+		Title:     title,
+		Generated: time.Now(),
+		PerPage:   perPage,
+		Posts:     make([]site.Post, len(posts)),
+	}
+	for i, post := range posts {
+		timeline.Posts[i] = site.Post{
+			Title:       postTitle(post.Title),
+			Url:         post.Url.String,
+			Description: post.Description.String,
+			Author:      post.Author.String,
+			Categories:  post.Categories,
+			PublishedAt: post.PublishedAt,
+			FeedName:    post.FeedName,
+			FeedUrl:     post.FeedUrl,
+		}
+	}
+
+	pages, err := site.Build(timeline, outDir, cmd.Options.String("templates"))
+	if err != nil {
+		return fmt.Errorf("error: unable to build site in %s: %v", outDir, err)
+	}
+
+	s.Out.Successf("Built %d page(s) from %d post(s) in %s", pages, len(posts), outDir)
+	if hidden > 0 {
+		s.Out.Println(s.Out.Style(hiddenNotice(hidden), output.Dim))
+	}
+
+	return nil
+}
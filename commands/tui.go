@@ -0,0 +1,544 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/Cmolloy36/gator/internal/output"
+	"golang.org/x/term"
+)
+
+const tuiPostLimit = 200
+
+type tuiPane int
+
+const (
+	paneFeeds tuiPane = iota
+	panePosts
+	paneReader
+)
+
+type tuiKey int
+
+const (
+	keyNone tuiKey = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyTab
+	keyPageUp
+	keyPageDown
+	keyQuit
+	keyRune
+)
+
+type tuiModel struct {
+	s    *State
+	user database.User
+
+	feeds   []database.GetFeedFollowsForUserRow
+	posts   []database.GetPostsForFeedWithStateRow
+	filters muteFilters
+	// hidden is the number of posts of the selected feed the filters hide
+	hidden int
+
+	focus        tuiPane
+	feedIdx      int
+	postIdx      int
+	feedOffset   int
+	postOffset   int
+	readerOffset int
+
+	width  int
+	height int
+	status string
+}
+
+func HandlerTUI(s *State, cmd Command, user database.User) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("error: \"tui\" must be run in a terminal")
+	}
+
+	m := &tuiModel{
+		s:    s,
+		user: user,
+	}
+
+	if err := m.loadFeeds(); err != nil {
+		return err
+	}
+
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred when entering raw mode: %v", err)
+	}
+	defer term.Restore(fd, oldState)
+
+	// Alternate screen buffer, hidden cursor
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	buf := make([]byte, 16)
+	for {
+		m.render()
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return fmt.Errorf("unexpected error occurred reading input: %v", err)
+		}
+
+		key, r := parseTUIKey(buf[:n])
+		if key == keyQuit || (key == keyRune && r == 'q') {
+			return nil
+		}
+
+		m.status = ""
+		m.handleKey(key, r)
+	}
+}
+
+func parseTUIKey(b []byte) (tuiKey, rune) {
+	if len(b) == 0 {
+		return keyNone, 0
+	}
+
+	if b[0] == 0x1b {
+		switch string(b) {
+		case "\x1b[A", "\x1bOA":
+			return keyUp, 0
+		case "\x1b[B", "\x1bOB":
+			return keyDown, 0
+		case "\x1b[C", "\x1bOC":
+			return keyRight, 0
+		case "\x1b[D", "\x1bOD":
+			return keyLeft, 0
+		case "\x1b[5~":
+			return keyPageUp, 0
+		case "\x1b[6~":
+			return keyPageDown, 0
+		case "\x1b":
+			return keyLeft, 0
+		}
+		return keyNone, 0
+	}
+
+	switch b[0] {
+	case 3: // Ctrl-C
+		return keyQuit, 0
+	case '\r', '\n':
+		return keyEnter, 0
+	case '\t':
+		return keyTab, 0
+	}
+
+	r, _ := utf8.DecodeRune(b)
+	return keyRune, r
+}
+
+func (m *tuiModel) handleKey(key tuiKey, r rune) {
+	if key == keyRune {
+		switch r {
+		case 'j':
+			key = keyDown
+		case 'k':
+			key = keyUp
+		case 'h':
+			key = keyLeft
+		case 'l':
+			key = keyRight
+		case ' ':
+			key = keyPageDown
+		case 'b':
+			key = keyPageUp
+		case 'r':
+			m.toggleRead()
+			return
+		case 's':
+			m.toggleStar()
+			return
+		case 'o':
+			m.openSelected()
+			return
+		case 'R':
+			if err := m.loadFeeds(); err != nil {
+				m.status = err.Error()
+			}
+			return
+		default:
+			return
+		}
+	}
+
+	switch key {
+	case keyUp:
+		m.move(-1)
+	case keyDown:
+		m.move(1)
+	case keyPageUp:
+		m.move(-m.paneHeight())
+	case keyPageDown:
+		m.move(m.paneHeight())
+	case keyLeft:
+		if m.focus > paneFeeds {
+			m.focus--
+		}
+	case keyRight, keyEnter:
+		m.advance()
+	case keyTab:
+		m.focus = (m.focus + 1) % 3
+		if m.focus == paneReader {
+			m.markSelectedRead()
+		}
+	}
+}
+
+func (m *tuiModel) move(delta int) {
+	switch m.focus {
+	case paneFeeds:
+		if len(m.feeds) == 0 {
+			return
+		}
+		m.feedIdx = clamp(m.feedIdx+delta, 0, len(m.feeds)-1)
+		if err := m.loadPosts(); err != nil {
+			m.status = err.Error()
+		}
+	case panePosts:
+		if len(m.posts) == 0 {
+			return
+		}
+		m.postIdx = clamp(m.postIdx+delta, 0, len(m.posts)-1)
+		m.readerOffset = 0
+	case paneReader:
+		m.readerOffset = max(m.readerOffset+delta, 0)
+	}
+}
+
+func (m *tuiModel) advance() {
+	switch m.focus {
+	case paneFeeds:
+		if len(m.posts) > 0 {
+			m.focus = panePosts
+		}
+	case panePosts:
+		if len(m.posts) > 0 {
+			m.focus = paneReader
+			m.markSelectedRead()
+		}
+	}
+}
+
+func (m *tuiModel) loadFeeds() error {
+	feeds, err := m.s.Db.GetFeedFollowsForUser(context.Background(), m.user.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in loadFeeds: %v", err)
+	}
+
+	filters, err := loadMuteFilters(context.Background(), m.s, m.user)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in loadFeeds: %v", err)
+	}
+
+	m.feeds = feeds
+	m.filters = filters
+	m.feedIdx = clamp(m.feedIdx, 0, max(len(feeds)-1, 0))
+
+	return m.loadPosts()
+}
+
+func (m *tuiModel) loadPosts() error {
+	m.posts = nil
+	m.hidden = 0
+	m.postIdx = 0
+	m.postOffset = 0
+	m.readerOffset = 0
+
+	if len(m.feeds) == 0 {
+		return nil
+	}
+
+	fetch := func(n int) ([]database.GetPostsForFeedWithStateRow, error) {
+		getPostsParams := database.GetPostsForFeedWithStateParams{
+			UserID: m.user.ID,
+			FeedID: m.feeds[m.feedIdx].FeedID,
+			Limit:  int32(n),
+		}
+		return m.s.Db.GetPostsForFeedWithState(context.Background(), getPostsParams)
+	}
+
+	posts, hidden, err := fetchVisible(tuiPostLimit, fetch, func(post database.GetPostsForFeedWithStateRow) bool {
+		return m.filters.hides(post.Title.String, post.Author.String, post.Categories, post.Url.String)
+	})
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in loadPosts: %v", err)
+	}
+
+	m.posts = posts
+	m.hidden = hidden
+
+	return nil
+}
+
+func (m *tuiModel) selectedPost() *database.GetPostsForFeedWithStateRow {
+	if len(m.posts) == 0 {
+		return nil
+	}
+	return &m.posts[m.postIdx]
+}
+
+func (m *tuiModel) markSelectedRead() {
+	post := m.selectedPost()
+	if post == nil || post.ReadAt.Valid {
+		return
+	}
+
+	now := time.Now()
+	markPostReadParams := database.MarkPostReadParams{
+		UserID:    m.user.ID,
+		PostID:    post.ID,
+		CreatedAt: now,
+	}
+
+	if err := m.s.Db.MarkPostRead(context.Background(), markPostReadParams); err != nil {
+		m.status = fmt.Sprintf("unable to mark post read: %v", err)
+		return
+	}
+
+	post.ReadAt.Time = now
+	post.ReadAt.Valid = true
+}
+
+func (m *tuiModel) toggleRead() {
+	post := m.selectedPost()
+	if post == nil {
+		return
+	}
+
+	if !post.ReadAt.Valid {
+		m.markSelectedRead()
+		return
+	}
+
+	markPostUnreadParams := database.MarkPostUnreadParams{
+		UserID:    m.user.ID,
+		PostID:    post.ID,
+		UpdatedAt: time.Now(),
+	}
+
+	if err := m.s.Db.MarkPostUnread(context.Background(), markPostUnreadParams); err != nil {
+		m.status = fmt.Sprintf("unable to mark post unread: %v", err)
+		return
+	}
+
+	post.ReadAt.Valid = false
+}
+
+func (m *tuiModel) toggleStar() {
+	post := m.selectedPost()
+	if post == nil {
+		return
+	}
+
+	setPostStarredParams := database.SetPostStarredParams{
+		UserID:    m.user.ID,
+		PostID:    post.ID,
+		CreatedAt: time.Now(),
+		Starred:   !post.Starred,
+	}
+
+	if err := m.s.Db.SetPostStarred(context.Background(), setPostStarredParams); err != nil {
+		m.status = fmt.Sprintf("unable to star post: %v", err)
+		return
+	}
+
+	post.Starred = !post.Starred
+}
+
+func (m *tuiModel) openSelected() {
+	post := m.selectedPost()
+	if post == nil {
+		return
+	}
+
+	if err := openInBrowser(post.Url.String); err != nil {
+		m.status = err.Error()
+		return
+	}
+
+	m.markSelectedRead()
+	m.status = fmt.Sprintf("opened %s", post.Url.String)
+}
+
+func (m *tuiModel) paneHeight() int {
+	// One row for the pane headers, one for the status bar
+	return max(m.height-2, 1)
+}
+
+func (m *tuiModel) render() {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+	m.width, m.height = width, height
+
+	feedWidth := max(width/5, 12)
+	postWidth := max(width*2/5, 20)
+	readerWidth := max(width-feedWidth-postWidth-2, 10)
+	rows := m.paneHeight()
+
+	feedLines := m.feedLines(feedWidth, rows)
+	postLines := m.postLines(postWidth, rows)
+	readerLines := m.readerLines(readerWidth, rows)
+
+	var sb strings.Builder
+	sb.WriteString("\x1b[H\x1b[2J")
+
+	sb.WriteString(m.header("Feeds", paneFeeds, feedWidth))
+	sb.WriteString("│")
+	sb.WriteString(m.header("Posts", panePosts, postWidth))
+	sb.WriteString("│")
+	sb.WriteString(m.header("Post", paneReader, readerWidth))
+	sb.WriteString("\r\n")
+
+	for i := 0; i < rows; i++ {
+		sb.WriteString(feedLines[i])
+		sb.WriteString("│")
+		sb.WriteString(postLines[i])
+		sb.WriteString("│")
+		sb.WriteString(readerLines[i])
+		sb.WriteString("\r\n")
+	}
+
+	status := m.status
+	if status == "" {
+		status = fmt.Sprintf("%s | j/k move  h/l/tab switch  enter open  r read  s star  o browser  R reload  q quit", m.user.Name)
+		if m.hidden > 0 {
+			status = fmt.Sprintf("%s | %d hidden by filters%s", m.user.Name, m.hidden, strings.TrimPrefix(status, m.user.Name))
+		}
+	}
+	sb.WriteString("\x1b[7m")
+	sb.WriteString(output.Fit(status, width))
+	sb.WriteString("\x1b[0m")
+
+	fmt.Print(sb.String())
+}
+
+func (m *tuiModel) header(title string, pane tuiPane, width int) string {
+	text := output.Fit(" "+title, width)
+	if m.focus == pane {
+		return "\x1b[1;4m" + text + "\x1b[0m"
+	}
+	return "\x1b[1m" + text + "\x1b[0m"
+}
+
+func (m *tuiModel) feedLines(width, rows int) []string {
+	m.feedOffset = scrollOffset(m.feedOffset, m.feedIdx, rows)
+
+	lines := make([]string, rows)
+	for i := range lines {
+		idx := m.feedOffset + i
+		if idx >= len(m.feeds) {
+			lines[i] = strings.Repeat(" ", width)
+			continue
+		}
+		lines[i] = m.selectable(output.Fit(" "+m.feeds[idx].FeedName, width), idx == m.feedIdx, paneFeeds)
+	}
+
+	if len(m.feeds) == 0 {
+		lines[0] = output.Fit(" (not following any feeds)", width)
+	}
+
+	return lines
+}
+
+func (m *tuiModel) postLines(width, rows int) []string {
+	m.postOffset = scrollOffset(m.postOffset, m.postIdx, rows)
+
+	lines := make([]string, rows)
+	for i := range lines {
+		idx := m.postOffset + i
+		if idx >= len(m.posts) {
+			lines[i] = strings.Repeat(" ", width)
+			continue
+		}
+
+		post := m.posts[idx]
+		marker := "●"
+		if post.ReadAt.Valid {
+			marker = " "
+		}
+		star := " "
+		if post.Starred {
+			star = "★"
+		}
+
+		text := fmt.Sprintf("%s%s %s %s", marker, star, post.PublishedAt.Format("2006-01-02"), postTitle(post.Title))
+		lines[i] = m.selectable(output.Fit(text, width), idx == m.postIdx, panePosts)
+	}
+
+	if len(m.posts) == 0 && len(m.feeds) > 0 {
+		lines[0] = output.Fit(" (no posts yet, run agg)", width)
+	}
+
+	return lines
+}
+
+func (m *tuiModel) readerLines(width, rows int) []string {
+	content := []string{}
+
+	post := m.selectedPost()
+	if post != nil {
+		content = append(content, output.Wrap(postTitle(post.Title), width-1)...)
+		content = append(content, "")
+		content = append(content, output.Fit(post.Url.String, width-1))
+		content = append(content, post.PublishedAt.Format("Mon, 02 Jan 2006 15:04"))
+		content = append(content, "")
+		content = append(content, output.Wrap(output.StripHTML(post.Description.String), width-1)...)
+	}
+
+	m.readerOffset = clamp(m.readerOffset, 0, max(len(content)-rows, 0))
+
+	lines := make([]string, rows)
+	for i := range lines {
+		idx := m.readerOffset + i
+		if idx >= len(content) {
+			lines[i] = strings.Repeat(" ", width)
+			continue
+		}
+		lines[i] = output.Fit(" "+content[idx], width)
+	}
+
+	return lines
+}
+
+func (m *tuiModel) selectable(text string, selected bool, pane tuiPane) string {
+	if !selected {
+		return text
+	}
+	if m.focus == pane {
+		return "\x1b[7m" + text + "\x1b[0m"
+	}
+	return "\x1b[1m" + text + "\x1b[0m"
+}
+
+// scrollOffset keeps idx visible within a window of rows lines.
+func scrollOffset(offset, idx, rows int) int {
+	if idx < offset {
+		return idx
+	}
+	if idx >= offset+rows {
+		return idx - rows + 1
+	}
+	return offset
+}
+
+func clamp(v, lo, hi int) int {
+	return min(max(v, lo), hi)
+}
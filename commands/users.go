@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/Cmolloy36/gator/internal/output"
+)
+
+func getUserByName(s *State, name string) (database.User, error) {
+	user, err := s.Db.GetUser(context.Background(), name)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return database.User{}, fmt.Errorf("unexpected error occurred: %v", err)
+		}
+		return database.User{}, fmt.Errorf("%s does not exist", name)
+	}
+	return user, nil
+}
+
+func HandlerDelUser(s *State, cmd Command, admin database.User) error {
+	user, err := getUserByName(s, cmd.Options.String("name"))
+	if err != nil {
+		return err
+	}
+
+	feeds, err := s.Db.GetUserFeeds(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerDelUser: %v", err)
+	}
+
+	follows, err := s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerDelUser: %v", err)
+	}
+
+	if len(feeds) > 0 {
+		s.Out.Printf("Feeds added by %s, removed with their posts and all their follows:\n", user.Name)
+		table := output.NewTable("FEED", "URL").SetStyle(1, output.Dim)
+		for _, feed := range feeds {
+			table.Row(output.Truncate(feed.Name, 30), feed.Url)
+		}
+		s.Out.Table(table)
+		s.Out.Println()
+	}
+
+	if len(follows) > 0 {
+		s.Out.Printf("Feeds followed by %s, unfollowed:\n", user.Name)
+		table := output.NewTable("FEED", "URL").SetStyle(1, output.Dim)
+		for _, follow := range follows {
+			table.Row(output.Truncate(follow.FeedName, 30), follow.FeedUrl)
+		}
+		s.Out.Table(table)
+		s.Out.Println()
+	}
+
+	plan, err := planResetUser(s, user.Name)
+	if err != nil {
+		return err
+	}
+	plan.done = fmt.Sprintf("Deleted user %s", user.Name)
+
+	wasCurrent := s.ConfigStruct.Current_user_name == user.Name
+
+	err = runResetPlan(s, cmd, plan)
+	if err != nil {
+		return err
+	}
+
+	if wasCurrent && s.ConfigStruct.Current_user_name == "" {
+		s.Out.Warnf("You are no longer logged in, run \"gator login <name>\"")
+	}
+
+	return nil
+}
+
+func HandlerRenameUser(s *State, cmd Command, admin database.User) error {
+	user, err := getUserByName(s, cmd.Options.String("old"))
+	if err != nil {
+		return err
+	}
+
+	newName := cmd.Options.String("new")
+	if newName == user.Name {
+		return fmt.Errorf("user is already named %s", newName)
+	}
+
+	_, err = s.Db.GetUser(context.Background(), newName)
+	if err == nil {
+		return fmt.Errorf("%s already exists", newName)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("unexpected error occurred in HandlerRenameUser: %v", err)
+	}
+
+	numFeeds, err := s.Db.CountFeedsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerRenameUser: %v", err)
+	}
+
+	follows, err := s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerRenameUser: %v", err)
+	}
+
+	renameUserParams := database.RenameUserParams{
+		ID:        user.ID,
+		Name:      newName,
+		UpdatedAt: time.Now(),
+	}
+
+	renamed, err := s.Db.RenameUser(context.Background(), renameUserParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in HandlerRenameUser: %v", err)
+	}
+
+	s.Out.Successf("Renamed user %s to %s", user.Name, renamed.Name)
+	s.Out.Printf("%d feed(s) and %d follow(s) now belong to %s\n", numFeeds, len(follows), renamed.Name)
+
+	if s.ConfigStruct.Current_user_name == user.Name {
+		err = s.ConfigStruct.SetUser(renamed.Name)
+		if err != nil {
+			return fmt.Errorf("unexpected error occurred in HandlerRenameUser: %v", err)
+		}
+		s.Out.Printf("Logged in as %s\n", renamed.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,538 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Cmolloy36/gator/internal/auth"
+	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/Cmolloy36/gator/internal/output"
+	"github.com/google/uuid"
+)
+
+const (
+	webhookEvent        = "post.created"
+	webhookSecretPrefix = "whsec_"
+	// A failed delivery is retried after webhookRetryDelay, doubling with
+	// each attempt, until webhookMaxAttempts is reached.
+	webhookMaxAttempts = 6
+	webhookRetryDelay  = time.Minute
+	webhookTimeout     = 10 * time.Second
+	// webhookBatchSize is how many deliveries agg sends per tick.
+	webhookBatchSize = 20
+	// webhookClaim is how long a batch is reserved for the agg process
+	// sending it. Claims left by a process that died expire after it.
+	webhookClaim = webhookBatchSize * webhookTimeout
+)
+
+// Delivery statuses
+const (
+	deliveryPending   = "pending"
+	deliveryDelivered = "delivered"
+	deliveryFailed    = "failed"
+)
+
+func HandlerWebhook(s *State, cmd Command, user database.User) error {
+	switch cmd.Options.String("action") {
+	case "add":
+		return addWebhook(s, cmd, user)
+	case "list":
+		return listWebhooks(s, user)
+	case "rm":
+		return removeWebhook(s, cmd, user)
+	case "log":
+		return listWebhookDeliveries(s, cmd, user)
+	}
+	return nil
+}
+
+func addWebhook(s *State, cmd Command, user database.User) error {
+	target := cmd.Options.String("target")
+	if target == "" {
+		return fmt.Errorf("error: \"webhook add\" needs the URL to deliver posts to")
+	}
+
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("error: %s is not an http(s) URL", target)
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(context.Background(), u.Hostname())
+	if err != nil {
+		return fmt.Errorf("error: unable to resolve %s: %v", u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if err := checkWebhookIP(addr.IP); err != nil {
+			return fmt.Errorf("error: %s: %v", target, err)
+		}
+	}
+
+	var feedID uuid.NullUUID
+	if ref := cmd.Options.String("feed"); ref != "" {
+		feed, err := resolveFeed(s, ref)
+		if err != nil {
+			return err
+		}
+		feedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+
+		following, err := isFollowing(s, user, feed)
+		if err != nil {
+			return err
+		}
+		if !following {
+			s.Out.Warnf("%s does not follow %s, the webhook fires only for followed feeds", user.Name, feed.Name)
+		}
+	}
+
+	secret := cmd.Options.String("secret")
+	if secret == "" {
+		token, _, err := auth.NewToken()
+		if err != nil {
+			return fmt.Errorf("unexpected error occurred in addWebhook: %v", err)
+		}
+		secret = webhookSecretPrefix + token
+	}
+
+	keyword := cmd.Options.String("keyword")
+
+	createWebhookParams := database.CreateWebhookParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Url:       target,
+		Secret:    secret,
+		FeedID:    feedID,
+		Keyword:   sql.NullString{String: keyword, Valid: keyword != ""},
+		CreatedAt: time.Now(),
+	}
+
+	webhook, err := s.Db.CreateWebhook(context.Background(), createWebhookParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in addWebhook: %v", err)
+	}
+
+	s.Out.Successf("Added webhook %s delivering new posts to %s", shortPostID(webhook.ID), webhook.Url)
+	if !cmd.Options.IsSet("secret") {
+		s.Out.Printf("Signing secret: %s\n", secret)
+		s.Out.Warnf("Store it now, it cannot be shown again")
+	}
+
+	return nil
+}
+
+func isFollowing(s *State, user database.User, feed database.Feed) (bool, error) {
+	follows, err := s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return false, fmt.Errorf("unexpected error occurred in isFollowing: %v", err)
+	}
+
+	for _, follow := range follows {
+		if follow.FeedID == feed.ID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func listWebhooks(s *State, user database.User) error {
+	webhooks, err := s.Db.GetWebhooksForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in listWebhooks: %v", err)
+	}
+
+	if s.Out.Structured() {
+		records := make([]webhookRecord, len(webhooks))
+		for i, webhook := range webhooks {
+			records[i] = newWebhookRecord(webhook)
+		}
+		return s.Out.Encode(records)
+	}
+
+	if len(webhooks) == 0 {
+		return fmt.Errorf("%s has no webhooks, add one with \"gator webhook add <url>\"", user.Name)
+	}
+
+	table := output.NewTable("ID", "URL", "FEED", "KEYWORD", "CREATED").
+		SetStyle(0, output.Dim)
+	for _, webhook := range webhooks {
+		feed := "all followed"
+		if webhook.FeedName.Valid {
+			feed = webhook.FeedName.String
+		}
+		table.Row(shortPostID(webhook.ID), webhook.Url, feed, webhook.Keyword.String, output.Ago(webhook.CreatedAt))
+	}
+	s.Out.Table(table)
+
+	return nil
+}
+
+// resolveWebhook finds the user's webhook whose id starts with ref.
+func resolveWebhook(s *State, user database.User, ref string) (database.GetWebhooksForUserRow, error) {
+	webhooks, err := s.Db.GetWebhooksForUser(context.Background(), user.ID)
+	if err != nil {
+		return database.GetWebhooksForUserRow{}, fmt.Errorf("unexpected error occurred in resolveWebhook: %v", err)
+	}
+
+	matches := []database.GetWebhooksForUserRow{}
+	for _, webhook := range webhooks {
+		if strings.HasPrefix(webhook.ID.String(), strings.ToLower(ref)) {
+			matches = append(matches, webhook)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return database.GetWebhooksForUserRow{}, fmt.Errorf("%s has no webhook %s", user.Name, ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return database.GetWebhooksForUserRow{}, fmt.Errorf("webhook id %s is ambiguous, use more of it", ref)
+	}
+}
+
+func removeWebhook(s *State, cmd Command, user database.User) error {
+	ref := cmd.Options.String("target")
+	if ref == "" {
+		return fmt.Errorf("error: \"webhook rm\" needs the id of a webhook (see \"gator webhook list\")")
+	}
+
+	webhook, err := resolveWebhook(s, user, ref)
+	if err != nil {
+		return err
+	}
+
+	deleteWebhookParams := database.DeleteWebhookParams{
+		ID:     webhook.ID,
+		UserID: user.ID,
+	}
+
+	err = s.Db.DeleteWebhook(context.Background(), deleteWebhookParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in removeWebhook: %v", err)
+	}
+
+	s.Out.Successf("Removed webhook %s (%s) and its delivery log", shortPostID(webhook.ID), webhook.Url)
+
+	return nil
+}
+
+func listWebhookDeliveries(s *State, cmd Command, user database.User) error {
+	limit := cmd.Options.Int("limit")
+	if limit < 1 {
+		return fmt.Errorf("error: --limit must be at least 1")
+	}
+
+	var webhookID uuid.NullUUID
+	if ref := cmd.Options.String("target"); ref != "" {
+		webhook, err := resolveWebhook(s, user, ref)
+		if err != nil {
+			return err
+		}
+		webhookID = uuid.NullUUID{UUID: webhook.ID, Valid: true}
+	}
+
+	getWebhookDeliveriesForUserParams := database.GetWebhookDeliveriesForUserParams{
+		UserID:    user.ID,
+		WebhookID: webhookID,
+		PageLimit: int32(limit),
+	}
+
+	deliveries, err := s.Db.GetWebhookDeliveriesForUser(context.Background(), getWebhookDeliveriesForUserParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in listWebhookDeliveries: %v", err)
+	}
+
+	if s.Out.Structured() {
+		records := make([]webhookDeliveryRecord, len(deliveries))
+		for i, delivery := range deliveries {
+			records[i] = newWebhookDeliveryRecord(delivery)
+		}
+		return s.Out.Encode(records)
+	}
+
+	if len(deliveries) == 0 {
+		s.Out.Println("No webhook deliveries yet")
+		return nil
+	}
+
+	table := output.NewTable("QUEUED", "WEBHOOK", "POST", "STATUS", "ATTEMPTS", "RESULT").
+		SetStyle(1, output.Dim)
+	for _, delivery := range deliveries {
+		table.Row(
+			output.Ago(delivery.CreatedAt),
+			shortPostID(delivery.WebhookID),
+			output.Truncate(postTitle(delivery.PostTitle), 40),
+			delivery.Status,
+			fmt.Sprintf("%d/%d", delivery.Attempts, webhookMaxAttempts),
+			deliveryResult(delivery),
+		)
+	}
+	s.Out.Table(table)
+
+	return nil
+}
+
+// deliveryResult describes the outcome of the latest attempt.
+func deliveryResult(delivery database.GetWebhookDeliveriesForUserRow) string {
+	result := ""
+	if delivery.ResponseStatus.Valid {
+		result = fmt.Sprintf("HTTP %d", delivery.ResponseStatus.Int32)
+	}
+	if delivery.Error.Valid && !delivery.ResponseStatus.Valid {
+		result = output.Truncate(delivery.Error.String, 40)
+	}
+	if delivery.Status == deliveryPending && delivery.Attempts > 0 && delivery.NextAttemptAt.Valid {
+		result += fmt.Sprintf(", retry at %s", delivery.NextAttemptAt.Time.Format(time.TimeOnly))
+	}
+	return strings.TrimPrefix(result, ", ")
+}
+
+type webhookPayload struct {
+	Event     string      `json:"event"`
+	WebhookID string      `json:"webhook_id"`
+	Text      string      `json:"text"`
+	Feed      webhookFeed `json:"feed"`
+	Post      webhookPost `json:"post"`
+}
+
+type webhookFeed struct {
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
+type webhookPost struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Url         string    `json:"url"`
+	Description string    `json:"description"`
+	Author      string    `json:"author"`
+	Categories  []string  `json:"categories"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// webhookMatches reports whether post passes the webhook's keyword filter,
+// a case-insensitive match on the title or description.
+func webhookMatches(webhook database.Webhook, post database.Post) bool {
+	if !webhook.Keyword.Valid {
+		return true
+	}
+	keyword := strings.ToLower(webhook.Keyword.String)
+	return strings.Contains(strings.ToLower(post.Title.String), keyword) ||
+		strings.Contains(strings.ToLower(post.Description.String), keyword)
+}
+
+// queueWebhookDeliveries records a pending delivery of each new post to
+// every matching webhook of the feed's followers, and returns how many
+// were left out because the webhook's owner filters the post. agg sends
+// them with deliverWebhooks.
+func queueWebhookDeliveries(s *State, feed database.Feed, posts []database.Post) (int, error) {
+	if len(posts) == 0 {
+		return 0, nil
+	}
+
+	webhooks, err := s.Db.GetWebhooksForFeed(context.Background(), feed.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	hidden := 0
+	ownerFilters := map[uuid.UUID]muteFilters{}
+	for _, webhook := range webhooks {
+		filters, ok := ownerFilters[webhook.UserID]
+		if !ok {
+			filters, err = loadMuteFilters(context.Background(), s, database.User{ID: webhook.UserID})
+			if err != nil {
+				return hidden, err
+			}
+			ownerFilters[webhook.UserID] = filters
+		}
+
+		for _, post := range posts {
+			if !webhookMatches(webhook, post) {
+				continue
+			}
+			if filters.hides(post.Title.String, post.Author.String, post.Categories, post.Url.String) {
+				hidden++
+				continue
+			}
+
+			payload, err := json.Marshal(webhookPayload{
+				Event:     webhookEvent,
+				WebhookID: webhook.ID.String(),
+				Text:      fmt.Sprintf("%s: %s %s", feed.Name, postTitle(post.Title), post.Url.String),
+				Feed:      webhookFeed{Name: feed.Name, Url: feed.Url},
+				Post: webhookPost{
+					ID:          post.ID.String(),
+					Title:       post.Title.String,
+					Url:         post.Url.String,
+					Description: post.Description.String,
+					Author:      post.Author.String,
+					Categories:  post.Categories,
+					PublishedAt: post.PublishedAt,
+				},
+			})
+			if err != nil {
+				return hidden, err
+			}
+
+			createWebhookDeliveryParams := database.CreateWebhookDeliveryParams{
+				ID:        uuid.New(),
+				WebhookID: webhook.ID,
+				PostID:    post.ID,
+				Payload:   string(payload),
+				CreatedAt: time.Now(),
+			}
+
+			err = s.Db.CreateWebhookDelivery(context.Background(), createWebhookDeliveryParams)
+			if err != nil {
+				return hidden, err
+			}
+		}
+	}
+
+	return hidden, nil
+}
+
+// webhookSignature is the value of the X-Gator-Signature header: the hex
+// HMAC-SHA256 of the body, keyed with the webhook's secret.
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookClient sends deliveries. It does not follow redirects, and it
+// checks every address it connects to, so a name that later resolves to
+// the host itself is refused too.
+var webhookClient = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: webhookTimeout,
+			Control: func(network, address string, c syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				return checkWebhookIP(net.ParseIP(host))
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: webhookTimeout,
+	},
+}
+
+// checkWebhookIP refuses loopback, link-local, private and unspecified
+// addresses, which would let a webhook reach services on the host running
+// agg or its network, such as cloud metadata endpoints.
+func checkWebhookIP(ip net.IP) error {
+	switch {
+	case ip == nil:
+		return fmt.Errorf("invalid address")
+	case ip.IsLoopback(), ip.IsUnspecified():
+		return fmt.Errorf("webhooks cannot be delivered to %s, a local address", ip)
+	case ip.IsLinkLocalUnicast(), ip.IsLinkLocalMulticast(), ip.IsInterfaceLocalMulticast():
+		return fmt.Errorf("webhooks cannot be delivered to %s, a link-local address", ip)
+	case ip.IsPrivate():
+		return fmt.Errorf("webhooks cannot be delivered to %s, a private address", ip)
+	}
+	return nil
+}
+
+// postWebhook sends one delivery and returns the response status, or 0 if
+// no response was received.
+func postWebhook(ctx context.Context, delivery database.ClaimDueWebhookDeliveriesRow) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, "POST", delivery.WebhookUrl, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gator")
+	req.Header.Set("X-Gator-Event", webhookEvent)
+	req.Header.Set("X-Gator-Delivery", delivery.ID.String())
+	req.Header.Set("X-Gator-Signature", webhookSignature(delivery.WebhookSecret, body))
+
+	res, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("%s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+// deliverWebhooks sends the deliveries that are due and records the
+// outcome of each attempt. Failed attempts are rescheduled with
+// exponential backoff until they run out of attempts. Deliveries are
+// claimed before they are sent, so several agg processes can share the
+// queue without sending any twice.
+func deliverWebhooks(s *State) error {
+	now := time.Now()
+	claimDueWebhookDeliveriesParams := database.ClaimDueWebhookDeliveriesParams{
+		ClaimedUntil: now.Add(webhookClaim),
+		Now:          now,
+		PageLimit:    webhookBatchSize,
+	}
+
+	deliveries, err := s.Db.ClaimDueWebhookDeliveries(context.Background(), claimDueWebhookDeliveriesParams)
+	if err != nil {
+		return fmt.Errorf("unexpected error occurred in deliverWebhooks: %v", err)
+	}
+
+	for _, delivery := range deliveries {
+		status, sendErr := postWebhook(context.Background(), delivery)
+		now := time.Now()
+
+		updateWebhookDeliveryParams := database.UpdateWebhookDeliveryParams{
+			ID:             delivery.ID,
+			Status:         deliveryDelivered,
+			Attempts:       delivery.Attempts + 1,
+			ResponseStatus: sql.NullInt32{Int32: int32(status), Valid: status != 0},
+		}
+
+		switch {
+		case sendErr == nil:
+			updateWebhookDeliveryParams.DeliveredAt = sql.NullTime{Time: now, Valid: true}
+			s.Out.Printf("%s post to webhook %s\n", s.Out.Style("Delivered", output.Green), delivery.WebhookUrl)
+		case updateWebhookDeliveryParams.Attempts >= webhookMaxAttempts:
+			updateWebhookDeliveryParams.Status = deliveryFailed
+			updateWebhookDeliveryParams.Error = sql.NullString{String: sendErr.Error(), Valid: true}
+			s.Out.Warnf("giving up on webhook delivery to %s after %d attempts: %v", delivery.WebhookUrl, webhookMaxAttempts, sendErr)
+		default:
+			retry := now.Add(webhookRetryDelay << (updateWebhookDeliveryParams.Attempts - 1))
+			updateWebhookDeliveryParams.Status = deliveryPending
+			updateWebhookDeliveryParams.NextAttemptAt = sql.NullTime{Time: retry, Valid: true}
+			updateWebhookDeliveryParams.Error = sql.NullString{String: sendErr.Error(), Valid: true}
+			s.Out.Warnf("webhook delivery to %s failed, retrying at %s: %v", delivery.WebhookUrl, retry.Format(time.TimeOnly), sendErr)
+		}
+
+		err := s.Db.UpdateWebhookDelivery(context.Background(), updateWebhookDeliveryParams)
+		if err != nil {
+			return fmt.Errorf("unexpected error occurred in deliverWebhooks: %v", err)
+		}
+	}
+
+	return nil
+}
@@ -3,13 +3,28 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
+	"time"
 )
 
 const configFileName = ".gatorconfig.json"
 
 type Config struct {
-	Db_url            string `json:"db_url"`
-	Current_user_name string `json:"current_user_name"`
+	Db_url             string      `json:"db_url"`
+	Current_user_name  string      `json:"current_user_name"`
+	Session_token      string      `json:"session_token,omitempty"`
+	Session_expires_at time.Time   `json:"session_expires_at,omitzero"`
+	Smtp               *SmtpConfig `json:"smtp,omitempty"`
+}
+
+// SmtpConfig is the mail server digests are sent through. Username and
+// Password may be left empty for servers that do not need them.
+type SmtpConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	From     string `json:"from"`
 }
 
 func Read() (Config, error) {
@@ -41,6 +56,22 @@ func (c *Config) SetUser(name string) error {
 	return nil
 }
 
+// SetSession logs name in with a session token that expires at expiresAt.
+func (c *Config) SetSession(name, token string, expiresAt time.Time) error {
+	c.Current_user_name = name
+	c.Session_token = token
+	c.Session_expires_at = expiresAt
+	return write(*c)
+}
+
+// Logout forgets the current user and their session.
+func (c *Config) Logout() error {
+	c.Current_user_name = ""
+	c.Session_token = ""
+	c.Session_expires_at = time.Time{}
+	return write(*c)
+}
+
 func getConfigFilePath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -49,6 +80,10 @@ func getConfigFilePath() (string, error) {
 	return homeDir + "/" + configFileName, nil
 }
 
+// write replaces the config file with config. It holds a session token
+// and possibly an SMTP password, so it is written to a new file only its
+// owner can read, then renamed over the old one: a config created with a
+// wider mode is tightened, and a failed write leaves the old one intact.
 func write(config Config) error {
 	prettyConfig, err := json.MarshalIndent(config, "", "    ")
 	if err != nil {
@@ -60,7 +95,28 @@ func write(config Config) error {
 		return err
 	}
 
-	os.WriteFile(configFile, prettyConfig, 0644)
+	// Write through a symlinked config, as kept in a dotfiles repo
+	if target, err := filepath.EvalSymlinks(configFile); err == nil {
+		configFile = target
+	}
 
-	return nil
+	tmp, err := os.CreateTemp(filepath.Dir(configFile), configFileName+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(prettyConfig); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), configFile)
 }
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWriteTightensMode checks that saving a config created readable by
+// others leaves it readable by its owner only.
+func TestWriteTightensMode(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path := filepath.Join(home, configFileName)
+	if err := os.WriteFile(path, []byte(`{"db_url": "postgres://localhost/gator"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetSession("alice", "token", cfg.Session_expires_at); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("config mode is %o after writing, want 600", mode)
+	}
+
+	saved, err := Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Db_url != cfg.Db_url || saved.Session_token != "token" {
+		t.Errorf("config read back as %+v", saved)
+	}
+
+	entries, _ := os.ReadDir(home)
+	if len(entries) != 1 {
+		t.Errorf("temporary files left next to the config: %v", entries)
+	}
+}
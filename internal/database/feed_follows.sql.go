@@ -12,6 +12,44 @@ import (
 	"github.com/google/uuid"
 )
 
+const countFeedFollows = `-- name: CountFeedFollows :one
+SELECT COUNT(*) FROM feed_follows
+`
+
+func (q *Queries) CountFeedFollows(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeedFollows)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countFeedFollowsAffectedByUser = `-- name: CountFeedFollowsAffectedByUser :one
+SELECT COUNT(*) FROM feed_follows
+WHERE user_id = $1 OR feed_id IN (
+    SELECT id FROM feeds
+    WHERE user_id = $1
+)
+`
+
+func (q *Queries) CountFeedFollowsAffectedByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeedFollowsAffectedByUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countFeedFollowsForFeed = `-- name: CountFeedFollowsForFeed :one
+SELECT COUNT(*) FROM feed_follows
+WHERE feed_id = $1
+`
+
+func (q *Queries) CountFeedFollowsForFeed(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeedFollowsForFeed, feedID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createFeedFollow = `-- name: CreateFeedFollow :one
 WITH inserted_feed_follow AS (INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
 VALUES (
@@ -72,6 +110,7 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
 SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id,
 feeds.name AS feed_name,
+feeds.url AS feed_url,
 users.name AS user_name
 FROM feed_follows
 INNER JOIN feeds
@@ -79,6 +118,7 @@ ON feed_follows.feed_id = feeds.id
 INNER JOIN users
 ON feed_follows.user_id = users.id
 WHERE feed_follows.user_id = $1
+ORDER BY feeds.name
 `
 
 type GetFeedFollowsForUserRow struct {
@@ -88,6 +128,7 @@ type GetFeedFollowsForUserRow struct {
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
 	FeedName  string
+	FeedUrl   string
 	UserName  string
 }
 
@@ -107,6 +148,7 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 			&i.UserID,
 			&i.FeedID,
 			&i.FeedName,
+			&i.FeedUrl,
 			&i.UserName,
 		); err != nil {
 			return nil, err
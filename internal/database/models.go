@@ -11,6 +11,39 @@ import (
 	"github.com/google/uuid"
 )
 
+type Alert struct {
+	ID        uuid.UUID
+	RuleID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type AlertRule struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Pattern   string
+	IsRegex   bool
+	FeedID    uuid.NullUUID
+	CreatedAt time.Time
+}
+
+type ApiKey struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	Name         string
+	Prefix       string
+	KeyHash      string
+	CreatedAt    time.Time
+	LastUsedAt   sql.NullTime
+	FeverKeyHash sql.NullString
+	Scope        string
+}
+
+type Digest struct {
+	UserID     uuid.UUID
+	LastSentAt time.Time
+}
+
 type Feed struct {
 	ID            uuid.UUID
 	CreatedAt     time.Time
@@ -19,6 +52,16 @@ type Feed struct {
 	Url           string
 	UserID        uuid.UUID
 	LastFetchedAt sql.NullTime
+	SeqID         int64
+}
+
+type FeedFetch struct {
+	ID         uuid.UUID
+	FeedID     uuid.UUID
+	FetchedAt  time.Time
+	DurationMs int64
+	ItemCount  int32
+	Error      sql.NullString
 }
 
 type FeedFollow struct {
@@ -29,6 +72,15 @@ type FeedFollow struct {
 	FeedID    uuid.UUID
 }
 
+type Filter struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Field     string
+	Pattern   string
+	IsRegex   bool
+	CreatedAt time.Time
+}
+
 type Post struct {
 	ID          uuid.UUID
 	CreatedAt   time.Time
@@ -38,11 +90,58 @@ type Post struct {
 	Description sql.NullString
 	PublishedAt time.Time
 	FeedID      uuid.UUID
+	SeqID       int64
+	Author      sql.NullString
+	Categories  []string
+	FetchedAt   time.Time
+	ProcessedAt sql.NullTime
+}
+
+type PostState struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ReadAt    sql.NullTime
+	Starred   bool
+}
+
+type Session struct {
+	TokenHash string
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
 }
 
 type User struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Name         string
+	PasswordHash sql.NullString
+	Role         string
+}
+
+type Webhook struct {
 	ID        uuid.UUID
+	UserID    uuid.UUID
+	Url       string
+	Secret    string
+	FeedID    uuid.NullUUID
+	Keyword   sql.NullString
 	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	WebhookID      uuid.UUID
+	PostID         uuid.UUID
+	Payload        string
+	Status         string
+	Attempts       int32
+	NextAttemptAt  sql.NullTime
+	ResponseStatus sql.NullInt32
+	Error          sql.NullString
+	CreatedAt      time.Time
+	DeliveredAt    sql.NullTime
 }
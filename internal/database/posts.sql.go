@@ -11,10 +11,62 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countFollowedPosts = `-- name: CountFollowedPosts :one
+SELECT COUNT(*) FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1
+`
+
+func (q *Queries) CountFollowedPosts(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFollowedPosts, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPosts = `-- name: CountPosts :one
+SELECT COUNT(*) FROM posts
+`
+
+func (q *Queries) CountPosts(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPosts)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPostsForFeed = `-- name: CountPostsForFeed :one
+SELECT COUNT(*) FROM posts
+WHERE feed_id = $1
+`
+
+func (q *Queries) CountPostsForFeed(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPostsForFeed, feedID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPostsForUserFeeds = `-- name: CountPostsForUserFeeds :one
+SELECT COUNT(*) FROM posts
+WHERE feed_id IN (
+    SELECT id FROM feeds
+    WHERE user_id = $1
+)
+`
+
+func (q *Queries) CountPostsForUserFeeds(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPostsForUserFeeds, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (ID, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (ID, created_at, updated_at, title, url, description, published_at, feed_id, author, categories, fetched_at)
 VALUES (
     $1,
     $2,
@@ -23,9 +75,13 @@ VALUES (
     $4,
     $5,
     $2,
-    $6
+    $6,
+    $7,
+    $8,
+    $9
 )
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+ON CONFLICT (url) DO NOTHING
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, seq_id, author, categories, fetched_at, processed_at
 `
 
 type CreatePostParams struct {
@@ -35,6 +91,9 @@ type CreatePostParams struct {
 	Url         sql.NullString
 	Description sql.NullString
 	FeedID      uuid.UUID
+	Author      sql.NullString
+	Categories  []string
+	FetchedAt   time.Time
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -45,7 +104,191 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Url,
 		arg.Description,
 		arg.FeedID,
+		arg.Author,
+		pq.Array(arg.Categories),
+		arg.FetchedAt,
+	)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.SeqID,
+		&i.Author,
+		pq.Array(&i.Categories),
+		&i.FetchedAt,
+		&i.ProcessedAt,
+	)
+	return i, err
+}
+
+const deleteAllPosts = `-- name: DeleteAllPosts :execrows
+DELETE FROM posts
+`
+
+func (q *Queries) DeleteAllPosts(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAllPosts)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deletePostsForFeed = `-- name: DeletePostsForFeed :execrows
+DELETE FROM posts
+WHERE feed_id = $1
+`
+
+func (q *Queries) DeletePostsForFeed(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePostsForFeed, feedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getFeedPostStats = `-- name: GetFeedPostStats :one
+SELECT MIN(published_at)::timestamp AS oldest,
+MAX(published_at)::timestamp AS newest,
+COUNT(*) FILTER (WHERE published_at >= $1) AS recent
+FROM posts
+WHERE feed_id = $2
+`
+
+type GetFeedPostStatsParams struct {
+	Since  time.Time
+	FeedID uuid.UUID
+}
+
+type GetFeedPostStatsRow struct {
+	Oldest time.Time
+	Newest time.Time
+	Recent int64
+}
+
+func (q *Queries) GetFeedPostStats(ctx context.Context, arg GetFeedPostStatsParams) (GetFeedPostStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedPostStats, arg.Since, arg.FeedID)
+	var i GetFeedPostStatsRow
+	err := row.Scan(
+		&i.Oldest,
+		&i.Newest,
+		&i.Recent,
+	)
+	return i, err
+}
+
+const getFeverItems = `-- name: GetFeverItems :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.seq_id, posts.author, posts.categories, posts.fetched_at, posts.processed_at,
+feeds.seq_id AS feed_seq_id,
+post_states.read_at,
+COALESCE(post_states.starred, FALSE) AS starred
+FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = $1
+WHERE ($2::bigint IS NULL OR posts.seq_id > $2)
+AND ($3::bigint IS NULL OR posts.seq_id < $3)
+AND ($4::bigint[] IS NULL OR posts.seq_id = ANY($4::bigint[]))
+ORDER BY CASE WHEN $3::bigint IS NOT NULL THEN posts.seq_id END DESC,
+posts.seq_id
+LIMIT $5
+`
+
+type GetFeverItemsParams struct {
+	UserID    uuid.UUID
+	SinceID   sql.NullInt64
+	MaxID     sql.NullInt64
+	WithIds   []int64
+	PageLimit int32
+}
+
+type GetFeverItemsRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       sql.NullString
+	Url         sql.NullString
+	Description sql.NullString
+	PublishedAt time.Time
+	FeedID      uuid.UUID
+	SeqID       int64
+	Author      sql.NullString
+	Categories  []string
+	FetchedAt   time.Time
+	ProcessedAt sql.NullTime
+	FeedSeqID   int64
+	ReadAt      sql.NullTime
+	Starred     bool
+}
+
+func (q *Queries) GetFeverItems(ctx context.Context, arg GetFeverItemsParams) ([]GetFeverItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeverItems,
+		arg.UserID,
+		arg.SinceID,
+		arg.MaxID,
+		pq.Array(arg.WithIds),
+		arg.PageLimit,
 	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeverItemsRow
+	for rows.Next() {
+		var i GetFeverItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SeqID,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.FetchedAt,
+			&i.ProcessedAt,
+			&i.FeedSeqID,
+			&i.ReadAt,
+			&i.Starred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFollowedPostBySeqID = `-- name: GetFollowedPostBySeqID :one
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.seq_id, posts.author, posts.categories, posts.fetched_at, posts.processed_at FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1
+WHERE posts.seq_id = $2
+`
+
+type GetFollowedPostBySeqIDParams struct {
+	UserID uuid.UUID
+	SeqID  int64
+}
+
+func (q *Queries) GetFollowedPostBySeqID(ctx context.Context, arg GetFollowedPostBySeqIDParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getFollowedPostBySeqID, arg.UserID, arg.SeqID)
 	var i Post
 	err := row.Scan(
 		&i.ID,
@@ -56,17 +299,365 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.SeqID,
+		&i.Author,
+		pq.Array(&i.Categories),
+		&i.FetchedAt,
+		&i.ProcessedAt,
+	)
+	return i, err
+}
+
+const getPendingPostsForFeed = `-- name: GetPendingPostsForFeed :many
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, seq_id, author, categories, fetched_at, processed_at FROM posts
+WHERE feed_id = $1 AND processed_at IS NULL
+ORDER BY published_at, seq_id
+`
+
+func (q *Queries) GetPendingPostsForFeed(ctx context.Context, feedID uuid.UUID) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingPostsForFeed, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SeqID,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.FetchedAt,
+			&i.ProcessedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostWithState = `-- name: GetPostWithState :one
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.seq_id, posts.author, posts.categories, posts.fetched_at, posts.processed_at,
+feeds.name AS feed_name,
+post_states.read_at,
+COALESCE(post_states.starred, FALSE) AS starred
+FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = $1
+WHERE posts.id = $2
+`
+
+type GetPostWithStateParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+type GetPostWithStateRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       sql.NullString
+	Url         sql.NullString
+	Description sql.NullString
+	PublishedAt time.Time
+	FeedID      uuid.UUID
+	SeqID       int64
+	Author      sql.NullString
+	Categories  []string
+	FetchedAt   time.Time
+	ProcessedAt sql.NullTime
+	FeedName    string
+	ReadAt      sql.NullTime
+	Starred     bool
+}
+
+func (q *Queries) GetPostWithState(ctx context.Context, arg GetPostWithStateParams) (GetPostWithStateRow, error) {
+	row := q.db.QueryRowContext(ctx, getPostWithState, arg.UserID, arg.PostID)
+	var i GetPostWithStateRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.SeqID,
+		&i.Author,
+		pq.Array(&i.Categories),
+		&i.FetchedAt,
+		&i.ProcessedAt,
+		&i.FeedName,
+		&i.ReadAt,
+		&i.Starred,
 	)
 	return i, err
 }
 
+const getPostsByIDPrefix = `-- name: GetPostsByIDPrefix :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.seq_id, posts.author, posts.categories, posts.fetched_at, posts.processed_at FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1
+WHERE posts.id::text LIKE $2::text || '%'
+ORDER BY posts.id
+LIMIT 2
+`
+
+type GetPostsByIDPrefixParams struct {
+	UserID uuid.UUID
+	Prefix string
+}
+
+func (q *Queries) GetPostsByIDPrefix(ctx context.Context, arg GetPostsByIDPrefixParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsByIDPrefix, arg.UserID, arg.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SeqID,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.FetchedAt,
+			&i.ProcessedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForFeedWithState = `-- name: GetPostsForFeedWithState :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.seq_id, posts.author, posts.categories, posts.fetched_at, posts.processed_at,
+post_states.read_at,
+COALESCE(post_states.starred, FALSE) AS starred
+FROM posts
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = $1
+WHERE posts.feed_id = $2
+ORDER BY posts.published_at DESC
+LIMIT $3
+`
+
+type GetPostsForFeedWithStateParams struct {
+	UserID uuid.UUID
+	FeedID uuid.UUID
+	Limit  int32
+}
+
+type GetPostsForFeedWithStateRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       sql.NullString
+	Url         sql.NullString
+	Description sql.NullString
+	PublishedAt time.Time
+	FeedID      uuid.UUID
+	SeqID       int64
+	Author      sql.NullString
+	Categories  []string
+	FetchedAt   time.Time
+	ProcessedAt sql.NullTime
+	ReadAt      sql.NullTime
+	Starred     bool
+}
+
+func (q *Queries) GetPostsForFeedWithState(ctx context.Context, arg GetPostsForFeedWithStateParams) ([]GetPostsForFeedWithStateRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForFeedWithState, arg.UserID, arg.FeedID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForFeedWithStateRow
+	for rows.Next() {
+		var i GetPostsForFeedWithStateRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SeqID,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.FetchedAt,
+			&i.ProcessedAt,
+			&i.ReadAt,
+			&i.Starred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForReaderStream = `-- name: GetPostsForReaderStream :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.seq_id, posts.author, posts.categories, posts.fetched_at, posts.processed_at,
+feeds.name AS feed_name,
+feeds.url AS feed_url,
+post_states.read_at,
+COALESCE(post_states.starred, FALSE) AS starred
+FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = $1
+WHERE ($2::uuid IS NULL OR posts.feed_id = $2)
+AND (NOT $3::bool OR post_states.read_at IS NULL)
+AND (NOT $4::bool OR COALESCE(post_states.starred, FALSE))
+AND ($5::timestamp IS NULL OR posts.published_at >= $5)
+AND ($6::timestamp IS NULL OR posts.published_at < $6)
+ORDER BY CASE WHEN $7::bool THEN posts.published_at END ASC,
+posts.published_at DESC, posts.seq_id
+LIMIT $8
+OFFSET $9
+`
+
+type GetPostsForReaderStreamParams struct {
+	UserID      uuid.UUID
+	FeedID      uuid.NullUUID
+	UnreadOnly  bool
+	StarredOnly bool
+	Since       sql.NullTime
+	Until       sql.NullTime
+	OldestFirst bool
+	PageLimit   int32
+	PageOffset  int32
+}
+
+type GetPostsForReaderStreamRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       sql.NullString
+	Url         sql.NullString
+	Description sql.NullString
+	PublishedAt time.Time
+	FeedID      uuid.UUID
+	SeqID       int64
+	Author      sql.NullString
+	Categories  []string
+	FetchedAt   time.Time
+	ProcessedAt sql.NullTime
+	FeedName    string
+	FeedUrl     string
+	ReadAt      sql.NullTime
+	Starred     bool
+}
+
+func (q *Queries) GetPostsForReaderStream(ctx context.Context, arg GetPostsForReaderStreamParams) ([]GetPostsForReaderStreamRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForReaderStream,
+		arg.UserID,
+		arg.FeedID,
+		arg.UnreadOnly,
+		arg.StarredOnly,
+		arg.Since,
+		arg.Until,
+		arg.OldestFirst,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForReaderStreamRow
+	for rows.Next() {
+		var i GetPostsForReaderStreamRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SeqID,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.FetchedAt,
+			&i.ProcessedAt,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.ReadAt,
+			&i.Starred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT id, created_at, updated_at, title, url, description, published_at, feed_id FROM posts
-WHERE feed_id IN (
-    SELECT feed_id FROM feeds
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.seq_id, posts.author, posts.categories, posts.fetched_at, posts.processed_at,
+feeds.name AS feed_name
+FROM posts
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE posts.feed_id IN (
+    SELECT feed_id FROM feed_follows
     WHERE user_id = $1
 )
-ORDER BY updated_at
+ORDER BY posts.published_at DESC, posts.seq_id
 LIMIT $2
 `
 
@@ -75,15 +666,352 @@ type GetPostsForUserParams struct {
 	Limit  int32
 }
 
-func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
+type GetPostsForUserRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       sql.NullString
+	Url         sql.NullString
+	Description sql.NullString
+	PublishedAt time.Time
+	FeedID      uuid.UUID
+	SeqID       int64
+	Author      sql.NullString
+	Categories  []string
+	FetchedAt   time.Time
+	ProcessedAt sql.NullTime
+	FeedName    string
+}
+
+func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
 	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Post
+	var items []GetPostsForUserRow
 	for rows.Next() {
-		var i Post
+		var i GetPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SeqID,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.FetchedAt,
+			&i.ProcessedAt,
+			&i.FeedName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserWithState = `-- name: GetPostsForUserWithState :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.seq_id, posts.author, posts.categories, posts.fetched_at, posts.processed_at,
+feeds.name AS feed_name,
+post_states.read_at,
+COALESCE(post_states.starred, FALSE) AS starred
+FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = $1
+WHERE ($2::uuid IS NULL OR posts.feed_id = $2)
+AND (NOT $3::bool OR post_states.read_at IS NULL)
+AND (NOT $4::bool OR COALESCE(post_states.starred, FALSE))
+AND ($5::timestamp IS NULL OR posts.published_at >= $5)
+ORDER BY posts.published_at DESC, posts.id
+LIMIT $6
+OFFSET $7
+`
+
+type GetPostsForUserWithStateParams struct {
+	UserID      uuid.UUID
+	FeedID      uuid.NullUUID
+	UnreadOnly  bool
+	StarredOnly bool
+	Since       sql.NullTime
+	PageLimit   int32
+	PageOffset  int32
+}
+
+type GetPostsForUserWithStateRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       sql.NullString
+	Url         sql.NullString
+	Description sql.NullString
+	PublishedAt time.Time
+	FeedID      uuid.UUID
+	SeqID       int64
+	Author      sql.NullString
+	Categories  []string
+	FetchedAt   time.Time
+	ProcessedAt sql.NullTime
+	FeedName    string
+	ReadAt      sql.NullTime
+	Starred     bool
+}
+
+func (q *Queries) GetPostsForUserWithState(ctx context.Context, arg GetPostsForUserWithStateParams) ([]GetPostsForUserWithStateRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserWithState,
+		arg.UserID,
+		arg.FeedID,
+		arg.UnreadOnly,
+		arg.StarredOnly,
+		arg.Since,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserWithStateRow
+	for rows.Next() {
+		var i GetPostsForUserWithStateRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SeqID,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.FetchedAt,
+			&i.ProcessedAt,
+			&i.FeedName,
+			&i.ReadAt,
+			&i.Starred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReaderItems = `-- name: GetReaderItems :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.seq_id, posts.author, posts.categories, posts.fetched_at, posts.processed_at,
+feeds.name AS feed_name,
+feeds.url AS feed_url,
+post_states.read_at,
+COALESCE(post_states.starred, FALSE) AS starred
+FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = $1
+WHERE posts.seq_id = ANY($2::bigint[])
+ORDER BY posts.published_at DESC, posts.seq_id
+`
+
+type GetReaderItemsParams struct {
+	UserID uuid.UUID
+	SeqIds []int64
+}
+
+type GetReaderItemsRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       sql.NullString
+	Url         sql.NullString
+	Description sql.NullString
+	PublishedAt time.Time
+	FeedID      uuid.UUID
+	SeqID       int64
+	Author      sql.NullString
+	Categories  []string
+	FetchedAt   time.Time
+	ProcessedAt sql.NullTime
+	FeedName    string
+	FeedUrl     string
+	ReadAt      sql.NullTime
+	Starred     bool
+}
+
+func (q *Queries) GetReaderItems(ctx context.Context, arg GetReaderItemsParams) ([]GetReaderItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReaderItems, arg.UserID, pq.Array(arg.SeqIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReaderItemsRow
+	for rows.Next() {
+		var i GetReaderItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SeqID,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.FetchedAt,
+			&i.ProcessedAt,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.ReadAt,
+			&i.Starred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStarredPostSeqIDs = `-- name: GetStarredPostSeqIDs :many
+SELECT posts.seq_id, posts.title, posts.url, posts.author, posts.categories FROM posts
+INNER JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = $1
+WHERE post_states.starred
+ORDER BY posts.seq_id
+`
+
+type GetStarredPostSeqIDsRow struct {
+	SeqID      int64
+	Title      sql.NullString
+	Url        sql.NullString
+	Author     sql.NullString
+	Categories []string
+}
+
+func (q *Queries) GetStarredPostSeqIDs(ctx context.Context, userID uuid.UUID) ([]GetStarredPostSeqIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getStarredPostSeqIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStarredPostSeqIDsRow
+	for rows.Next() {
+		var i GetStarredPostSeqIDsRow
+		if err := rows.Scan(
+			&i.SeqID,
+			&i.Title,
+			&i.Url,
+			&i.Author,
+			pq.Array(&i.Categories),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTimelinePosts = `-- name: GetTimelinePosts :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.seq_id, posts.author, posts.categories, posts.fetched_at, posts.processed_at,
+feeds.name AS feed_name,
+feeds.url AS feed_url
+FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE ($2::text IS NULL OR EXISTS (
+    SELECT 1 FROM unnest(posts.categories) AS category
+    WHERE lower(category) = lower($2)
+))
+AND ($3::text IS NULL
+    OR position(lower($3) IN lower(posts.title)) > 0
+    OR position(lower($3) IN lower(posts.description)) > 0)
+AND ($4::timestamp IS NULL OR posts.fetched_at >= $4)
+AND ($5::timestamp IS NULL OR posts.fetched_at < $5)
+ORDER BY posts.published_at DESC, posts.seq_id DESC
+LIMIT $6
+`
+
+type GetTimelinePostsParams struct {
+	UserID       uuid.UUID
+	Tag          sql.NullString
+	Keyword      sql.NullString
+	FetchedSince sql.NullTime
+	FetchedUntil sql.NullTime
+	PageLimit    int32
+}
+
+type GetTimelinePostsRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       sql.NullString
+	Url         sql.NullString
+	Description sql.NullString
+	PublishedAt time.Time
+	FeedID      uuid.UUID
+	SeqID       int64
+	Author      sql.NullString
+	Categories  []string
+	FetchedAt   time.Time
+	ProcessedAt sql.NullTime
+	FeedName    string
+	FeedUrl     string
+}
+
+func (q *Queries) GetTimelinePosts(ctx context.Context, arg GetTimelinePostsParams) ([]GetTimelinePostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTimelinePosts,
+		arg.UserID,
+		arg.Tag,
+		arg.Keyword,
+		arg.FetchedSince,
+		arg.FetchedUntil,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTimelinePostsRow
+	for rows.Next() {
+		var i GetTimelinePostsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
@@ -93,6 +1021,13 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.SeqID,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.FetchedAt,
+			&i.ProcessedAt,
+			&i.FeedName,
+			&i.FeedUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -106,3 +1041,66 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const getUnreadPostSeqIDs = `-- name: GetUnreadPostSeqIDs :many
+SELECT posts.seq_id, posts.title, posts.url, posts.author, posts.categories FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = $1
+WHERE post_states.read_at IS NULL
+ORDER BY posts.seq_id
+`
+
+type GetUnreadPostSeqIDsRow struct {
+	SeqID      int64
+	Title      sql.NullString
+	Url        sql.NullString
+	Author     sql.NullString
+	Categories []string
+}
+
+func (q *Queries) GetUnreadPostSeqIDs(ctx context.Context, userID uuid.UUID) ([]GetUnreadPostSeqIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreadPostSeqIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUnreadPostSeqIDsRow
+	for rows.Next() {
+		var i GetUnreadPostSeqIDsRow
+		if err := rows.Scan(
+			&i.SeqID,
+			&i.Title,
+			&i.Url,
+			&i.Author,
+			pq.Array(&i.Categories),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markPostsProcessed = `-- name: MarkPostsProcessed :exec
+UPDATE posts
+SET processed_at = $1
+WHERE id = ANY($2::uuid[])
+`
+
+type MarkPostsProcessedParams struct {
+	ProcessedAt time.Time
+	Ids         []uuid.UUID
+}
+
+func (q *Queries) MarkPostsProcessed(ctx context.Context, arg MarkPostsProcessedParams) error {
+	_, err := q.db.ExecContext(ctx, markPostsProcessed, arg.ProcessedAt, pq.Array(arg.Ids))
+	return err
+}
@@ -0,0 +1,187 @@
+// Package digest composes digest emails of new posts, grouped by feed, as
+// multipart HTML and plain text MIME messages, and sends them over SMTP.
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Post struct {
+	Title       string
+	Url         string
+	Author      string
+	Summary     string
+	PublishedAt time.Time
+}
+
+// Group holds the posts of one feed.
+type Group struct {
+	Feed  string
+	Posts []Post
+}
+
+type Digest struct {
+	From    string
+	To      []string
+	Subject string
+	Since   time.Time
+	Date    time.Time
+	Groups  []Group
+	// Hidden is the number of posts left out by the recipient's filters.
+	Hidden int
+	// Truncated is set when there were more new posts than the digest
+	// holds, and only the newest are listed.
+	Truncated bool
+}
+
+// Total is the number of posts in the digest.
+func (d Digest) Total() int {
+	total := 0
+	for _, group := range d.Groups {
+		total += len(group.Posts)
+	}
+	return total
+}
+
+var textTemplate = template.Must(template.New("text").Parse(`{{.Total}} new post(s) since {{.Since.Format "Mon Jan 2 15:04"}}{{with .Hidden}}, {{.}} hidden by your filters{{end}}
+{{if .Truncated}}Only the newest {{.Total}} are listed, older posts were left out (see "gator browse").
+{{end}}{{range .Groups}}
+{{.Feed}}
+{{range .Posts}}
+  * {{.Title}}{{with .Author}} ({{.}}){{end}}
+    {{.Url}}
+{{with .Summary}}    {{.}}
+{{end}}{{end}}{{end}}`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; max-width: 40em;">
+<p>{{.Total}} new post(s) since {{.Since.Format "Mon Jan 2 15:04"}}{{with .Hidden}}, {{.}} hidden by your filters{{end}}</p>
+{{if .Truncated}}<p>Only the newest {{.Total}} are listed, older posts were left out (see "gator browse").</p>
+{{end}}{{range .Groups}}
+<h2 style="font-size: 1.1em; border-bottom: 1px solid #ddd;">{{.Feed}}</h2>
+<ul style="padding-left: 1.2em;">
+{{range .Posts}}
+<li style="margin-bottom: 0.8em;">
+<a href="{{.Url}}">{{.Title}}</a>{{with .Author}} <span style="color: #777;">({{.}})</span>{{end}}
+{{with .Summary}}<br><span style="color: #555;">{{.}}</span>{{end}}
+</li>
+{{end}}
+</ul>
+{{end}}
+</body>
+</html>
+`))
+
+// Message renders d as a multipart/alternative MIME message with a plain
+// text and an HTML part.
+func (d Digest) Message() ([]byte, error) {
+	var text, html bytes.Buffer
+	if err := textTemplate.Execute(&text, d); err != nil {
+		return nil, err
+	}
+	if err := htmlTemplate.Execute(&html, d); err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write(part.content); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&msg, "%s: %s\r\n", name, value)
+	}
+
+	header("From", d.From)
+	if len(d.To) > 0 {
+		header("To", strings.Join(d.To, ", "))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", d.Subject))
+	header("Date", d.Date.Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@gator>", uuid.New()))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+// Server is an SMTP server to send through. Authentication is only used
+// when Username is set.
+type Server struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// Send delivers msg to every address in to. Addresses may include a name,
+// as in "Gator <gator@example.com>". STARTTLS is used when the server
+// offers it.
+func Send(server Server, from string, to []string, msg []byte) error {
+	envelopeFrom, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %v", from, err)
+	}
+
+	recipients := make([]string, len(to))
+	for i, address := range to {
+		recipient, err := mail.ParseAddress(address)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %v", address, err)
+		}
+		recipients[i] = recipient.Address
+	}
+
+	var auth smtp.Auth
+	if server.Username != "" {
+		auth = smtp.PlainAuth("", server.Username, server.Password, server.Host)
+	}
+
+	addr := net.JoinHostPort(server.Host, strconv.Itoa(server.Port))
+	return smtp.SendMail(addr, auth, envelopeFrom.Address, recipients, msg)
+}
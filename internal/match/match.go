@@ -0,0 +1,61 @@
+// Package match compiles the patterns users write for alert and filter
+// rules: plain words, matched as a whole-word phrase, or regular
+// expressions. Both are case-insensitive.
+package match
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Pattern struct {
+	text  string
+	regex bool
+	re    *regexp.Regexp
+}
+
+// Compile parses text as a regular expression if regex is set, and as a
+// phrase of plain words otherwise. A regular expression may turn off case
+// insensitivity with (?-i).
+func Compile(text string, regex bool) (*Pattern, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("pattern is empty")
+	}
+
+	expr := text
+	if !regex {
+		// Word boundaries that also work next to symbols, as in "C++"
+		words := strings.Fields(text)
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		expr = `(^|\W)` + strings.Join(words, `\s+`) + `($|\W)`
+	}
+
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %v", text, err)
+	}
+
+	return &Pattern{text: text, regex: regex, re: re}, nil
+}
+
+// Match reports whether any of fields matches the pattern.
+func (p *Pattern) Match(fields ...string) bool {
+	for _, field := range fields {
+		if p.re.MatchString(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the pattern as written, with regular expressions between
+// slashes.
+func (p *Pattern) String() string {
+	if p.regex {
+		return "/" + p.text + "/"
+	}
+	return p.text
+}
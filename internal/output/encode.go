@@ -0,0 +1,224 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+type Format int
+
+const (
+	Text Format = iota
+	JSON
+	CSV
+	YAML
+)
+
+var formatNames = map[string]Format{
+	"text": Text,
+	"json": JSON,
+	"csv":  CSV,
+	"yaml": YAML,
+}
+
+func ParseFormat(name string) (Format, error) {
+	format, ok := formatNames[strings.ToLower(name)]
+	if !ok {
+		return Text, fmt.Errorf("error: unknown output format \"%s\" (expected text, json, csv or yaml)", name)
+	}
+	return format, nil
+}
+
+// Reset returns p to human-readable output.
+func (p *Printer) Reset() {
+	p.format = Text
+	p.template = nil
+}
+
+func (p *Printer) SetFormat(format Format) {
+	p.format = format
+}
+
+// Structured reports whether handlers should emit records through Encode
+// instead of their human-readable output.
+func (p *Printer) Structured() bool {
+	return p.format != Text || p.template != nil
+}
+
+// Encode writes records, a slice of flat structs, in the selected
+// structured format or through the --format template. Column names come
+// from the fields' json tags.
+func (p *Printer) Encode(records any) error {
+	v := reflect.ValueOf(records)
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("output: Encode expects a slice, got %s", v.Kind())
+	}
+
+	if p.template != nil {
+		return encodeTemplate(p.w, p.template, v)
+	}
+
+	switch p.format {
+	case JSON:
+		return encodeJSON(p.w, v)
+	case CSV:
+		return encodeCSV(p.w, v)
+	case YAML:
+		return encodeYAML(p.w, v)
+	}
+
+	return fmt.Errorf("output: Encode called without a structured format")
+}
+
+type recordField struct {
+	name  string
+	index int
+}
+
+func recordFields(t reflect.Type) []recordField {
+	fields := []recordField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		fields = append(fields, recordField{name: name, index: i})
+	}
+	return fields
+}
+
+func encodeJSON(w io.Writer, v reflect.Value) error {
+	if v.IsNil() {
+		v = reflect.MakeSlice(v.Type(), 0, 0)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v.Interface())
+}
+
+func encodeTemplate(w io.Writer, tmpl *template.Template, v reflect.Value) error {
+	for i := 0; i < v.Len(); i++ {
+		if err := tmpl.Execute(w, v.Index(i).Interface()); err != nil {
+			return fmt.Errorf("error: unable to render --format template: %v", err)
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeCSV(w io.Writer, v reflect.Value) error {
+	fields := recordFields(v.Type().Elem())
+
+	cw := csv.NewWriter(w)
+
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = f.name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		row := make([]string, len(fields))
+		for j, f := range fields {
+			row[j], _ = scalarString(v.Index(i).Field(f.index))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func encodeYAML(w io.Writer, v reflect.Value) error {
+	if v.Len() == 0 {
+		_, err := fmt.Fprintln(w, "[]")
+		return err
+	}
+
+	fields := recordFields(v.Type().Elem())
+
+	var sb strings.Builder
+	for i := 0; i < v.Len(); i++ {
+		for j, f := range fields {
+			prefix := "  "
+			if j == 0 {
+				prefix = "- "
+			}
+
+			value, quote := scalarString(v.Index(i).Field(f.index))
+			switch {
+			case value == "" && !quote:
+				value = "null"
+			case quote:
+				quoted, _ := json.Marshal(value)
+				value = string(quoted)
+			}
+
+			sb.WriteString(prefix + f.name + ": " + value + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// scalarString formats a record field for CSV and YAML. The boolean result
+// reports whether the value is a string that YAML needs quoted; nil
+// pointers produce an empty, unquoted value.
+func scalarString(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339Nano), true
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), false
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), false
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), true
+	}
+
+	return fmt.Sprint(v.Interface()), true
+}
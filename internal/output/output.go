@@ -0,0 +1,207 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+type Style int
+
+const (
+	Plain Style = iota
+	Bold
+	Dim
+	Red
+	Green
+	Yellow
+	Blue
+	Cyan
+)
+
+var styleCodes = map[Style]string{
+	Bold:   "1",
+	Dim:    "2",
+	Red:    "31",
+	Green:  "32",
+	Yellow: "33",
+	Blue:   "34",
+	Cyan:   "36",
+}
+
+// Printer writes human-readable output, colouring it only when the
+// destination is a terminal and NO_COLOR is not set. Warnings printed to
+// stdout go to stderr instead, so they stay out of output piped to a file
+// or another program.
+type Printer struct {
+	w         io.Writer
+	color     bool
+	warnW     io.Writer
+	warnColor bool
+	format    Format
+	template  *template.Template
+}
+
+func New(w io.Writer) *Printer {
+	warnW := w
+	if w == os.Stdout {
+		warnW = os.Stderr
+	}
+
+	return &Printer{
+		w:         w,
+		color:     colorEnabled(w),
+		warnW:     warnW,
+		warnColor: colorEnabled(warnW),
+	}
+}
+
+func colorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	return term.IsTerminal(int(f.Fd()))
+}
+
+func (p *Printer) Writer() io.Writer {
+	return p.w
+}
+
+func (p *Printer) Printf(format string, a ...any) {
+	fmt.Fprintf(p.w, format, a...)
+}
+
+func (p *Printer) Println(a ...any) {
+	fmt.Fprintln(p.w, a...)
+}
+
+// Successf prints a confirmation line for a completed action.
+func (p *Printer) Successf(format string, a ...any) {
+	fmt.Fprintln(p.w, p.Style(fmt.Sprintf(format, a...), Green))
+}
+
+// Warnf prints a line the user should pay attention to.
+func (p *Printer) Warnf(format string, a ...any) {
+	fmt.Fprintln(p.warnW, paint(fmt.Sprintf(format, a...), Yellow, p.warnColor))
+}
+
+func (p *Printer) Style(s string, style Style) string {
+	return paint(s, style, p.color)
+}
+
+func paint(s string, style Style, color bool) string {
+	code, ok := styleCodes[style]
+	if !color || !ok || s == "" {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// Fields prints label/value pairs with the values aligned.
+func (p *Printer) Fields(pairs ...string) {
+	width := 0
+	for i := 0; i < len(pairs); i += 2 {
+		width = max(width, utf8.RuneCountInString(pairs[i]))
+	}
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		label := Pad(pairs[i]+":", width+1)
+		fmt.Fprintf(p.w, "%s %s\n", p.Style(label, Bold), pairs[i+1])
+	}
+}
+
+// Table is a set of rows printed with aligned columns.
+type Table struct {
+	headers   []string
+	styles    []Style
+	rows      [][]string
+	rowStyles []Style
+}
+
+func NewTable(headers ...string) *Table {
+	return &Table{
+		headers: headers,
+		styles:  make([]Style, len(headers)),
+	}
+}
+
+// SetStyle colours every cell of column col.
+func (t *Table) SetStyle(col int, style Style) *Table {
+	if col >= 0 && col < len(t.styles) {
+		t.styles[col] = style
+	}
+	return t
+}
+
+func (t *Table) Row(cells ...string) {
+	t.StyledRow(Plain, cells...)
+}
+
+// StyledRow adds a row whose cells are all rendered in style, overriding
+// the column styles.
+func (t *Table) StyledRow(style Style, cells ...string) {
+	row := make([]string, len(t.headers))
+	copy(row, cells)
+	t.rows = append(t.rows, row)
+	t.rowStyles = append(t.rowStyles, style)
+}
+
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+func (p *Printer) Table(t *Table) {
+	widths := make([]int, len(t.headers))
+	for i, header := range t.headers {
+		widths[i] = utf8.RuneCountInString(header)
+	}
+	for _, row := range t.rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	last := len(t.headers) - 1
+
+	var sb strings.Builder
+	for i, header := range t.headers {
+		cell := header
+		if i < last {
+			cell = Pad(header, widths[i]) + "  "
+		}
+		sb.WriteString(p.Style(cell, Bold))
+	}
+	fmt.Fprintln(p.w, strings.TrimRight(sb.String(), " "))
+
+	for r, row := range t.rows {
+		sb.Reset()
+		for i, cell := range row {
+			padded := cell
+			if i < last {
+				padded = Pad(cell, widths[i])
+			}
+
+			style := t.styles[i]
+			if t.rowStyles[r] != Plain {
+				style = t.rowStyles[r]
+			}
+			sb.WriteString(p.Style(padded, style))
+
+			if i < last {
+				sb.WriteString("  ")
+			}
+		}
+		fmt.Fprintln(p.w, strings.TrimRight(sb.String(), " "))
+	}
+}
@@ -0,0 +1,64 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateFuncs are available to --format templates in addition to the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	"ago": func(v any) string {
+		t, ok := templateTime(v)
+		if !ok {
+			return "never"
+		}
+		return Ago(t)
+	},
+	"date": func(layout string, v any) string {
+		t, ok := templateTime(v)
+		if !ok {
+			return ""
+		}
+		return t.Format(layout)
+	},
+	"truncate": func(n int, s string) string {
+		return Truncate(s, n)
+	},
+	"pad": func(n int, s string) string {
+		return Pad(s, n)
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+func templateTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, !t.IsZero()
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, false
+		}
+		return *t, true
+	}
+	return time.Time{}, false
+}
+
+// SetTemplate makes Encode render each record through a Go template, one
+// line per record, e.g. '{{.PublishedAt | ago}} {{.Title}}'.
+func (p *Printer) SetTemplate(text string) error {
+	tmpl, err := template.New("format").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return fmt.Errorf("error: invalid --format template: %v", err)
+	}
+	p.template = tmpl
+	return nil
+}
@@ -0,0 +1,161 @@
+package output
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var htmlTagRegexp = regexp.MustCompile(`(?s)<[^>]*>`)
+var blankLinesRegexp = regexp.MustCompile(`\n{3,}`)
+
+// Ago describes t relative to now, e.g. "just now", "3h ago" or "in 2d".
+// Times more than a year away are printed as a date.
+func Ago(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	d := time.Since(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var amount string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		amount = fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		amount = fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 7*24*time.Hour:
+		amount = fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		amount = fmt.Sprintf("%dw", int(d.Hours()/(24*7)))
+	default:
+		return t.Format("2006-01-02")
+	}
+
+	if future {
+		return "in " + amount
+	}
+	return amount + " ago"
+}
+
+// stripControl drops control characters such as ESC, which a feed could
+// use to send escape sequences to the terminal, and keeps the ones in keep.
+func stripControl(s string, keep string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && !strings.ContainsRune(keep, r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// Truncate shortens s to at most n runes, marking the cut with an ellipsis.
+// Whitespace is collapsed and other control characters are dropped.
+func Truncate(s string, n int) string {
+	s = stripControl(strings.Join(strings.Fields(s), " "), "")
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
+
+// Pad right-pads s with spaces to width runes.
+func Pad(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// Fit truncates or pads s to exactly width runes, flattening newlines and
+// tabs and dropping other control characters so the result occupies a
+// single line.
+func Fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, s)
+
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		runes := []rune(s)
+		return string(runes[:width-1]) + "…"
+	}
+
+	return s + strings.Repeat(" ", width-n)
+}
+
+// Wrap word-wraps s to lines no wider than width runes.
+func Wrap(s string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+
+	lines := []string{}
+	for _, paragraph := range strings.Split(s, "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := ""
+		for _, word := range words {
+			for utf8.RuneCountInString(word) > width {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				runes := []rune(word)
+				lines = append(lines, string(runes[:width]))
+				word = string(runes[width:])
+			}
+
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// StripHTML turns an HTML fragment from a feed description into plain text.
+// Control characters other than newlines and tabs are dropped, including
+// ones written as entities such as &#27;.
+func StripHTML(s string) string {
+	s = strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n", "</p>", "\n\n", "<p>", "").Replace(s)
+	s = htmlTagRegexp.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = stripControl(s, "\n\t")
+	s = blankLinesRegexp.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,21 @@
+package output
+
+import "testing"
+
+// TestControlCharacters checks that text from feeds cannot carry escape
+// sequences or other control characters to the terminal.
+func TestControlCharacters(t *testing.T) {
+	title := "Go\x1b[2J\x1b]0;pwned\x07 1.26\u009b31m\r\nis out"
+
+	if got, want := Truncate(title, 40), "Go[2J]0;pwned 1.2631m is out"; got != want {
+		t.Errorf("Truncate(%q) = %q, want %q", title, got, want)
+	}
+	if got, want := Fit(title, 30), "Go[2J]0;pwned 1.2631m is out  "; got != want {
+		t.Errorf("Fit(%q) = %q, want %q", title, got, want)
+	}
+
+	description := "<p>Go&#27;[31m 1.26</p>\r\n<p>\tis out\x00</p>"
+	if got, want := StripHTML(description), "Go[31m 1.26\n\n\tis out"; got != want {
+		t.Errorf("StripHTML(%q) = %q, want %q", description, got, want)
+	}
+}
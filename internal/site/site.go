@@ -0,0 +1,353 @@
+// Package site renders a timeline of posts into a static HTML site: a
+// paginated index, a paginated page per feed and a page per day, each with
+// an index page of its own.
+package site
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Cmolloy36/gator/internal/output"
+)
+
+//go:embed templates
+var embedded embed.FS
+
+// Templates that may be overridden by a file of the same name in the
+// template directory. Page templates define "content", which layout.html
+// places in the page.
+const (
+	layoutTemplate = "layout.html"
+	listTemplate   = "list.html"
+	feedsTemplate  = "feeds.html"
+	daysTemplate   = "days.html"
+	styleSheet     = "style.css"
+)
+
+const summaryLen = 300
+
+type Post struct {
+	Title       string
+	Url         string
+	Description string
+	Author      string
+	Categories  []string
+	PublishedAt time.Time
+	FeedName    string
+	FeedUrl     string
+	FeedSlug    string
+}
+
+type Feed struct {
+	Name  string
+	Url   string
+	Slug  string
+	Posts int
+}
+
+type Day struct {
+	Date  string
+	Posts int
+}
+
+// Site is the input of Build. Posts are expected newest first.
+type Site struct {
+	Title     string
+	Generated time.Time
+	PerPage   int
+	Posts     []Post
+}
+
+// Page is the data every template is executed with. Root is the relative
+// path from the page back to the top of the site, e.g. "../../".
+type Page struct {
+	Site  *Site
+	Title string
+	Root  string
+	Posts []Post
+	Page  int
+	Pages int
+	Prev  string
+	Next  string
+	Feeds []Feed
+	Days  []Day
+}
+
+// Build writes the site to outDir, using templates from templateDir (if
+// not empty) in place of the embedded ones. It returns the number of pages
+// written. Existing files are overwritten but nothing is removed.
+func Build(site *Site, outDir, templateDir string) (int, error) {
+	if site.PerPage < 1 {
+		return 0, fmt.Errorf("posts per page must be at least 1")
+	}
+
+	templates, err := loadTemplates(templateDir)
+	if err != nil {
+		return 0, err
+	}
+
+	b := &builder{site: site, outDir: outDir, templates: templates}
+
+	feeds := assignSlugs(site.Posts)
+	days := groupByDay(site.Posts)
+
+	if err := b.writeList("", "", site.Posts); err != nil {
+		return b.pages, err
+	}
+
+	err = b.write("feeds/index.html", feedsTemplate, &Page{Title: "Feeds", Feeds: feeds})
+	if err != nil {
+		return b.pages, err
+	}
+
+	for _, feed := range feeds {
+		posts := []Post{}
+		for _, post := range site.Posts {
+			if post.FeedSlug == feed.Slug {
+				posts = append(posts, post)
+			}
+		}
+		if err := b.writeList("feeds/"+feed.Slug+"/", feed.Name, posts); err != nil {
+			return b.pages, err
+		}
+	}
+
+	err = b.write("days/index.html", daysTemplate, &Page{Title: "Archive", Days: days})
+	if err != nil {
+		return b.pages, err
+	}
+
+	for _, day := range days {
+		posts := []Post{}
+		for _, post := range site.Posts {
+			if post.PublishedAt.Format(time.DateOnly) == day.Date {
+				posts = append(posts, post)
+			}
+		}
+		err := b.write("days/"+day.Date+".html", listTemplate, &Page{Title: day.Date, Posts: posts, Page: 1, Pages: 1})
+		if err != nil {
+			return b.pages, err
+		}
+	}
+
+	style, err := readTemplate(templateDir, styleSheet)
+	if err != nil {
+		return b.pages, err
+	}
+	if err := os.WriteFile(filepath.Join(outDir, styleSheet), []byte(style), 0o644); err != nil {
+		return b.pages, err
+	}
+
+	return b.pages, nil
+}
+
+type builder struct {
+	site      *Site
+	outDir    string
+	templates map[string]*template.Template
+	pages     int
+}
+
+// writeList writes posts as numbered pages under dir: dir/index.html, then
+// dir/page/2.html and so on.
+func (b *builder) writeList(dir, title string, posts []Post) error {
+	pages := max(1, (len(posts)+b.site.PerPage-1)/b.site.PerPage)
+
+	for n := 1; n <= pages; n++ {
+		page := &Page{
+			Title: title,
+			Posts: posts[min((n-1)*b.site.PerPage, len(posts)):min(n*b.site.PerPage, len(posts))],
+			Page:  n,
+			Pages: pages,
+		}
+
+		root := rootOf(listPagePath(dir, n))
+		if n > 1 {
+			page.Prev = root + listPagePath(dir, n-1)
+		}
+		if n < pages {
+			page.Next = root + listPagePath(dir, n+1)
+		}
+
+		if err := b.write(listPagePath(dir, n), listTemplate, page); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func listPagePath(dir string, n int) string {
+	if n == 1 {
+		return dir + "index.html"
+	}
+	return fmt.Sprintf("%spage/%d.html", dir, n)
+}
+
+// rootOf returns the relative path from the page at name to the top of the
+// site.
+func rootOf(name string) string {
+	return strings.Repeat("../", strings.Count(name, "/"))
+}
+
+func (b *builder) write(name, templateName string, page *Page) error {
+	page.Site = b.site
+	page.Root = rootOf(name)
+
+	filename := filepath.Join(b.outDir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := b.templates[templateName].ExecuteTemplate(file, layoutTemplate, page); err != nil {
+		return fmt.Errorf("unable to render %s: %v", name, err)
+	}
+
+	b.pages++
+	return file.Close()
+}
+
+var funcs = template.FuncMap{
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	"summary": func(description string) string {
+		return output.Truncate(output.StripHTML(description), summaryLen)
+	},
+}
+
+// loadTemplates parses each page template together with the layout,
+// preferring files in templateDir over the embedded ones.
+func loadTemplates(templateDir string) (map[string]*template.Template, error) {
+	layout, err := readTemplate(templateDir, layoutTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := template.New(layoutTemplate).Funcs(funcs).Parse(layout)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", layoutTemplate, err)
+	}
+
+	templates := map[string]*template.Template{}
+	for _, name := range []string{listTemplate, feedsTemplate, daysTemplate} {
+		text, err := readTemplate(templateDir, name)
+		if err != nil {
+			return nil, err
+		}
+
+		t := template.Must(base.Clone())
+		if _, err := t.New(name).Parse(text); err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %v", name, err)
+		}
+		templates[name] = t
+	}
+
+	return templates, nil
+}
+
+func readTemplate(templateDir, name string) (string, error) {
+	if templateDir != "" {
+		data, err := os.ReadFile(filepath.Join(templateDir, name))
+		if err == nil {
+			return string(data), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	data, err := embedded.ReadFile(path.Join("templates", name))
+	return string(data), err
+}
+
+// assignSlugs gives each feed in posts a unique slug, in place, and returns
+// the feeds ordered by name.
+func assignSlugs(posts []Post) []Feed {
+	byUrl := map[string]*Feed{}
+	used := map[string]bool{}
+	feeds := []*Feed{}
+
+	for i, post := range posts {
+		feed, ok := byUrl[post.FeedUrl]
+		if !ok {
+			slug := Slug(post.FeedName)
+			for n := 2; used[slug]; n++ {
+				slug = fmt.Sprintf("%s-%d", Slug(post.FeedName), n)
+			}
+			used[slug] = true
+
+			feed = &Feed{Name: post.FeedName, Url: post.FeedUrl, Slug: slug}
+			byUrl[post.FeedUrl] = feed
+			feeds = append(feeds, feed)
+		}
+		feed.Posts++
+		posts[i].FeedSlug = feed.Slug
+	}
+
+	sorted := make([]Feed, len(feeds))
+	for i, feed := range feeds {
+		sorted[i] = *feed
+	}
+	slices.SortFunc(sorted, func(a, b Feed) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+
+	return sorted
+}
+
+// groupByDay counts posts per publication day, newest day first.
+func groupByDay(posts []Post) []Day {
+	days := []Day{}
+	index := map[string]int{}
+
+	for _, post := range posts {
+		date := post.PublishedAt.Format(time.DateOnly)
+		i, ok := index[date]
+		if !ok {
+			i = len(days)
+			index[date] = i
+			days = append(days, Day{Date: date})
+		}
+		days[i].Posts++
+	}
+
+	return days
+}
+
+// Slug turns a name into a lowercase, URL-safe path segment.
+func Slug(name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteRune('-')
+			dash = true
+		}
+	}
+
+	slug := strings.TrimSuffix(sb.String(), "-")
+	if slug == "" {
+		return "feed"
+	}
+	return slug
+}
@@ -1,25 +1,28 @@
 package main
 
 import (
+	"bufio"
 	"database/sql"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/Cmolloy36/gator/commands"
 	"github.com/Cmolloy36/gator/internal/config"
 	"github.com/Cmolloy36/gator/internal/database"
+	"github.com/Cmolloy36/gator/internal/output"
 	_ "github.com/lib/pq"
 )
 
 func main() {
 	cfg, err := config.Read()
 	if err != nil {
-		fmt.Println(fmt.Errorf("error: %w", err))
+		fmt.Fprintln(os.Stderr, fmt.Errorf("error: %w", err))
 	}
 
 	db, err := sql.Open("postgres", cfg.Db_url)
 	if err != nil {
-		fmt.Println(fmt.Errorf("error: %w", err))
+		fmt.Fprintln(os.Stderr, fmt.Errorf("error: %w", err))
 	}
 
 	dbQueries := database.New(db)
@@ -27,39 +30,369 @@ func main() {
 	st := &commands.State{
 		Db:           dbQueries,
 		ConfigStruct: &cfg,
+		Out:          output.New(os.Stdout),
+		In:           bufio.NewReader(os.Stdin),
 	}
 
 	commandsStruct := commands.Commands{
 		FunctionMap: make(map[string]func(*commands.State, commands.Command) error),
+		InfoMap:     make(map[string]commands.CommandInfo),
 	}
 
 	// Handler Commands
 
-	commandsStruct.Register("addfeed", commands.MiddlewareLoggedIn(commands.HandlerAddFeed))
+	commandsStruct.RegisterWithRole("addfeed", commands.CommandInfo{
+		Description: "Add a new feed to collect from and follow it",
+		Args:        []commands.Arg{{Name: "name"}, {Name: "url"}},
+		Examples:    []string{"gator addfeed \"Boot.dev Blog\" https://blog.boot.dev/index.xml"},
+	}, commands.RoleMember, commands.HandlerAddFeed)
 
-	commandsStruct.Register("agg", commands.HandlerAggregator)
+	commandsStruct.Register("agg", commands.CommandInfo{
+		Description: "Run in the background, collecting posts from feeds",
+		Args:        []commands.Arg{{Name: "interval", Kind: commands.DurationOption, Optional: true, Default: "5s"}},
+		Flags: []commands.Flag{
+			{Name: "digest-to", Usage: "Also email the logged in user's digest to these comma-separated addresses"},
+			{Name: "digest-every", Kind: commands.DurationOption, Default: "24h", Usage: "How often to send the digest"},
+		},
+		Examples: []string{"gator agg", "gator agg 1m", "gator agg 5m --digest-to team@example.com --digest-every 168h"},
+	}, commands.HandlerAggregator)
 
-	commandsStruct.Register("browse", commands.MiddlewareLoggedIn(commands.HandlerBrowser))
+	commandsStruct.RegisterLoggedIn("alert", commands.CommandInfo{
+		Description: "Add, list or remove alert rules matched against new posts",
+		Args: []commands.Arg{
+			{Name: "action", Values: []string{"add", "list", "rm"}},
+			{Name: "pattern", Optional: true},
+		},
+		Flags: append([]commands.Flag{
+			{Name: "feed", Usage: "Only match posts from this feed, by URL or name", Complete: commands.CompleteFeedURLs},
+			{Name: "regex", Short: "r", Kind: commands.BoolOption, Usage: "Treat the pattern as a regular expression"},
+		}, commands.OutputFlags...),
+		Examples: []string{
+			"gator alert add \"Exchange Server\"",
+			"gator alert add 'CVE-2026-\\d+' --regex --feed https://msrc.microsoft.com/blog/feed",
+			"gator alert list",
+			"gator alert rm 1a2b3c4d",
+		},
+	}, commands.HandlerAlert)
 
-	commandsStruct.Register("feeds", commands.HandlerFeeds)
+	commandsStruct.RegisterLoggedIn("alerts", commands.CommandInfo{
+		Description: "Show posts that matched your alert rules, newest first",
+		Flags: append([]commands.Flag{
+			{Name: "limit", Short: "n", Kind: commands.IntOption, Default: "20", Usage: "Number of alerts to show"},
+			{Name: "since", Kind: commands.DurationOption, Usage: "Only show alerts raised this long ago or later"},
+		}, commands.OutputFlags...),
+		Examples: []string{"gator alerts", "gator alerts --since 24h --output json"},
+	}, commands.HandlerAlerts)
 
-	commandsStruct.Register("follow", commands.MiddlewareLoggedIn(commands.HandlerFollow))
+	commandsStruct.RegisterLoggedIn("apikey", commands.CommandInfo{
+		Description: "Create, list or revoke API keys for the HTTP API",
+		Args: []commands.Arg{
+			{Name: "action", Values: []string{"create", "list", "revoke"}},
+			{Name: "name", Optional: true},
+		},
+		Flags: append([]commands.Flag{
+			{Name: "feed", Kind: commands.BoolOption, Usage: "Create a key that only reads the timeline feeds, for ?key= URLs"},
+		}, commands.OutputFlags...),
+		Examples: []string{"gator apikey create slack-bot", "gator apikey create reader --feed", "gator apikey list", "gator apikey revoke slack-bot"},
+	}, commands.HandlerAPIKey)
 
-	commandsStruct.Register("following", commands.MiddlewareLoggedIn(commands.HandlerFollowing))
+	commandsStruct.RegisterLoggedIn("browse", commands.CommandInfo{
+		Description: "Browse posts from followed feeds",
+		Args:        []commands.Arg{{Name: "limit", Kind: commands.IntOption, Optional: true}},
+		Flags: append([]commands.Flag{
+			{Name: "limit", Short: "n", Kind: commands.IntOption, Default: "2", Usage: "Number of posts to show"},
+		}, commands.OutputFlags...),
+		Examples: []string{"gator browse 10", "gator browse --limit 10 --output json"},
+	}, commands.HandlerBrowser)
 
-	commandsStruct.Register("login", commands.HandlerLogin)
+	commandsStruct.Register("completion", commands.CommandInfo{
+		Description: "Print a shell completion script",
+		Args:        []commands.Arg{{Name: "shell", Values: []string{"bash", "zsh", "fish"}}},
+		Examples: []string{
+			"source <(gator completion bash)",
+			"source <(gator completion zsh)",
+			"gator completion fish | source",
+		},
+	}, commands.HandlerCompletion)
 
-	commandsStruct.Register("register", commands.HandlerRegister)
+	commandsStruct.Register("__complete", commands.CommandInfo{
+		Hidden:  true,
+		RawArgs: true,
+	}, commandsStruct.HandlerComplete)
 
-	commandsStruct.Register("reset", commands.HandlerReset)
+	commandsStruct.RegisterLoggedIn("digest", commands.CommandInfo{
+		Description: "Email a digest of new posts, grouped by feed",
+		Flags: []commands.Flag{
+			{Name: "since", Kind: commands.DurationOption, Default: "24h", Usage: "Include posts published this long ago or later"},
+			{Name: "to", Usage: "Comma-separated recipient addresses"},
+			{Name: "print", Kind: commands.BoolOption, Usage: "Print the MIME message instead of sending it"},
+		},
+		Examples: []string{
+			"gator digest --to me@example.com",
+			"gator digest --since 168h --to team@example.com",
+			"gator digest --print > digest.eml",
+		},
+	}, commands.HandlerDigest)
 
-	commandsStruct.Register("unfollow", commands.MiddlewareLoggedIn(commands.HandlerUnfollow))
+	commandsStruct.RegisterWithRole("deluser", commands.CommandInfo{
+		Description: "Delete a user with the feeds they added and their follows",
+		Args:        []commands.Arg{{Name: "name", Complete: commands.CompleteUsers}},
+		Flags: []commands.Flag{
+			{Name: "dry-run", Kind: commands.BoolOption, Usage: "Only report what would be deleted"},
+			commands.YesFlag,
+		},
+		Examples: []string{"gator deluser alice", "gator deluser alice --dry-run"},
+	}, commands.RoleAdmin, commands.HandlerDelUser)
 
-	commandsStruct.Register("users", commands.HandlerUsers)
+	commandsStruct.Register("feedinfo", commands.CommandInfo{
+		Description: "Show owner, activity and fetch statistics for a feed",
+		Args:        []commands.Arg{{Name: "feed", Complete: commands.CompleteFeedURLs}},
+		Flags:       commands.OutputFlags,
+		Examples:    []string{"gator feedinfo https://blog.boot.dev/index.xml", "gator feedinfo \"Boot.dev Blog\" --output json"},
+	}, commands.HandlerFeedInfo)
+
+	commandsStruct.Register("feeds", commands.CommandInfo{
+		Description: "View all feeds",
+		Flags:       commands.OutputFlags,
+	}, commands.HandlerFeeds)
+
+	commandsStruct.RegisterLoggedIn("filter", commands.CommandInfo{
+		Description: "Add, list or remove filters that hide matching posts",
+		Args: []commands.Arg{
+			{Name: "action", Values: []string{"add", "list", "rm"}},
+			{Name: "pattern", Optional: true},
+		},
+		Flags: append([]commands.Flag{
+			{Name: "field", Default: "any", Usage: "Post field to match", Values: []string{"any", "title", "author", "category", "url"}},
+			{Name: "regex", Short: "r", Kind: commands.BoolOption, Usage: "Treat the pattern as a regular expression"},
+		}, commands.OutputFlags...),
+		Examples: []string{
+			"gator filter add sponsored",
+			"gator filter add webinar --field title",
+			"gator filter add 'example\\.com/(ads|promo)/' --field url --regex",
+			"gator filter list",
+			"gator filter rm sponsored",
+		},
+	}, commands.HandlerFilter)
+
+	commandsStruct.RegisterWithRole("follow", commands.CommandInfo{
+		Description: "Follow an existing feed",
+		Args:        []commands.Arg{{Name: "url", Complete: commands.CompleteFeedURLs}},
+		Examples:    []string{"gator follow https://blog.boot.dev/index.xml"},
+	}, commands.RoleMember, commands.HandlerFollow)
+
+	commandsStruct.RegisterLoggedIn("following", commands.CommandInfo{
+		Description: "List the feeds the current user follows",
+		Flags:       commands.OutputFlags,
+	}, commands.HandlerFollowing)
+
+	commandsStruct.RegisterWithRole("grant", commands.CommandInfo{
+		Description: "Give a user a role: admin, member or read-only",
+		Args: []commands.Arg{
+			{Name: "name", Complete: commands.CompleteUsers},
+			{Name: "role", Values: commands.Roles},
+		},
+		Examples: []string{"gator grant alice admin", "gator grant bob read-only"},
+	}, commands.RoleAdmin, commands.HandlerGrant)
+
+	commandsStruct.Register("help", commands.CommandInfo{
+		Description: "Show available commands or help for one command",
+		Args:        []commands.Arg{{Name: "command", Optional: true, Complete: commands.CompleteCommands}},
+		Examples:    []string{"gator help", "gator help browse"},
+	}, commandsStruct.HandlerHelp)
+
+	commandsStruct.Register("login", commands.CommandInfo{
+		Description: "Log in as an existing user, asking for their password if they have one",
+		Args:        []commands.Arg{{Name: "name", Complete: commands.CompleteUsers}},
+		Flags: []commands.Flag{
+			{Name: "ttl", Kind: commands.DurationOption, Default: "720h", Usage: "How long the session lasts"},
+		},
+		Examples: []string{"gator login alice", "gator login alice --ttl 8h"},
+	}, commands.HandlerLogin)
+
+	commandsStruct.Register("logout", commands.CommandInfo{
+		Description: "End the current session",
+	}, commands.HandlerLogout)
+
+	commandsStruct.RegisterLoggedIn("open", commands.CommandInfo{
+		Description: "Open a post, by id or browse index, in the browser and mark it read",
+		Args:        []commands.Arg{{Name: "post"}},
+		Examples:    []string{"gator open 3", "gator open 1f3a9c2e"},
+	}, commands.HandlerOpen)
+
+	commandsStruct.RegisterLoggedIn("passwd", commands.CommandInfo{
+		Description: "Set, change or remove the current user's password",
+		Flags: []commands.Flag{
+			{Name: "remove", Kind: commands.BoolOption, Usage: "Remove the password instead of setting one"},
+		},
+		Examples: []string{"gator passwd", "gator passwd --remove"},
+	}, commands.HandlerPasswd)
+
+	commandsStruct.RegisterLoggedIn("publish", commands.CommandInfo{
+		Description: "Write your timeline as an Atom or RSS feed, optionally filtered by tag or keyword",
+		Args:        []commands.Arg{{Name: "kind", Optional: true, Default: "atom", Values: []string{"atom", "rss"}}},
+		Flags: []commands.Flag{
+			{Name: "tag", Short: "t", Usage: "Only posts with this category"},
+			{Name: "keyword", Short: "k", Usage: "Only posts whose title or description contains this"},
+			{Name: "limit", Short: "n", Kind: commands.IntOption, Default: "50", Usage: "Number of posts to include"},
+			{Name: "title", Usage: "Title of the feed"},
+			{Name: "link", Usage: "URL the feed is published at (required for rss)"},
+			{Name: "file", Short: "f", Usage: "Write to this file instead of stdout"},
+		},
+		Examples: []string{
+			"gator publish > timeline.atom",
+			"gator publish rss --tag golang --link https://example.com/go.rss --file go.rss",
+			"gator publish --keyword postgres --limit 20",
+		},
+	}, commands.HandlerPublish)
+
+	commandsStruct.RegisterLoggedIn("read", commands.CommandInfo{
+		Description: "Print a post, by id or browse index, and mark it read",
+		Args:        []commands.Arg{{Name: "post"}},
+		Examples:    []string{"gator read 3", "gator read 1f3a9c2e"},
+	}, commands.HandlerRead)
+
+	commandsStruct.Register("register", commands.CommandInfo{
+		Description: "Register a new user and log in as them",
+		Args:        []commands.Arg{{Name: "name"}},
+		Flags: []commands.Flag{
+			{Name: "password", Kind: commands.BoolOption, Usage: "Protect the user with a password, asked for without echo"},
+		},
+		Examples: []string{"gator register alice", "gator register alice --password"},
+	}, commands.HandlerRegister)
+
+	commandsStruct.RegisterWithRole("renamefeed", commands.CommandInfo{
+		Description: "Rename a feed you added",
+		Args:        []commands.Arg{{Name: "feed", Complete: commands.CompleteFeedURLs}, {Name: "name"}},
+		Examples:    []string{"gator renamefeed https://blog.boot.dev/index.xml \"Boot.dev Blog\""},
+	}, commands.RoleMember, commands.HandlerRenameFeed)
+
+	commandsStruct.RegisterWithRole("renameuser", commands.CommandInfo{
+		Description: "Rename a user, keeping their feeds and follows",
+		Args:        []commands.Arg{{Name: "old", Complete: commands.CompleteUsers}, {Name: "new"}},
+		Examples:    []string{"gator renameuser alice alicia"},
+	}, commands.RoleAdmin, commands.HandlerRenameUser)
+
+	commandsStruct.RegisterWithRole("reset", commands.CommandInfo{
+		Description: "Delete all users, feeds and posts, or only part of them",
+		Flags: []commands.Flag{
+			{Name: "posts", Kind: commands.BoolOption, Usage: "Delete all posts, keeping users, feeds and follows"},
+			{Name: "feed", Usage: "Delete the posts and fetch history of one feed, by URL or name", Complete: commands.CompleteFeedURLs},
+			{Name: "user", Usage: "Delete one user with their feeds and follows", Complete: commands.CompleteUsers},
+			{Name: "dry-run", Kind: commands.BoolOption, Usage: "Only report how many rows would be deleted"},
+			commands.YesFlag,
+		},
+		Examples: []string{
+			"gator reset --dry-run",
+			"gator reset --posts --yes",
+			"gator reset --feed https://blog.boot.dev/index.xml",
+			"gator reset --user alice",
+		},
+	}, commands.RoleAdmin, commands.HandlerReset)
+
+	commandsStruct.RegisterWithRole("rmfeed", commands.CommandInfo{
+		Description: "Remove a feed you added, with its posts and follows",
+		Args:        []commands.Arg{{Name: "feed", Complete: commands.CompleteFeedURLs}},
+		Flags:       []commands.Flag{commands.YesFlag},
+		Examples:    []string{"gator rmfeed https://blog.boot.dev/index.xml", "gator rmfeed \"Boot.dev Blog\" --yes"},
+	}, commands.RoleMember, commands.HandlerRmFeed)
+
+	commandsStruct.Register("serve", commands.CommandInfo{
+		Description: "Serve the JSON, Google Reader and Fever APIs over HTTP, authenticating requests by API key",
+		Flags: []commands.Flag{
+			{Name: "addr", Default: ":8080", Usage: "Address to listen on"},
+		},
+		Examples: []string{"gator serve", "gator serve --addr 127.0.0.1:9000"},
+	}, commands.HandlerServe)
+
+	commandsStruct.RegisterWithRole("setfeedurl", commands.CommandInfo{
+		Description: "Change the URL of a feed you added, keeping its follows",
+		Args:        []commands.Arg{{Name: "feed", Complete: commands.CompleteFeedURLs}, {Name: "url"}},
+		Flags: []commands.Flag{
+			{Name: "purge-posts", Kind: commands.BoolOption, Usage: "Delete posts fetched from the old URL"},
+			commands.YesFlag,
+		},
+		Examples: []string{"gator setfeedurl \"Boot.dev Blog\" https://www.boot.dev/blog/index.xml"},
+	}, commands.RoleMember, commands.HandlerSetFeedURL)
+
+	commandsStruct.RegisterWithRole("revoke", commands.CommandInfo{
+		Description: "Take a role away from a user, leaving them with the next lower one",
+		Args: []commands.Arg{
+			{Name: "name", Complete: commands.CompleteUsers},
+			{Name: "role", Values: []string{commands.RoleAdmin, commands.RoleMember}},
+		},
+		Examples: []string{"gator revoke alice admin", "gator revoke bob member"},
+	}, commands.RoleAdmin, commands.HandlerRevoke)
+
+	commandsStruct.Register("shell", commands.CommandInfo{
+		Description: "Interactive prompt that runs commands over one connection",
+		Examples:    []string{"gator shell", "gator shell < commands.txt"},
+	}, commandsStruct.HandlerShell)
+
+	commandsStruct.RegisterLoggedIn("site", commands.CommandInfo{
+		Description: "Build a static HTML site from your timeline",
+		Args: []commands.Arg{
+			{Name: "action", Values: []string{"build"}},
+			{Name: "outdir"},
+		},
+		Flags: []commands.Flag{
+			{Name: "templates", Usage: "Directory with templates overriding the built-in ones"},
+			{Name: "title", Usage: "Title of the site"},
+			{Name: "per-page", Kind: commands.IntOption, Default: "25", Usage: "Posts per index and feed page"},
+			{Name: "limit", Short: "n", Kind: commands.IntOption, Default: "1000", Usage: "Number of posts to include"},
+			{Name: "tag", Short: "t", Usage: "Only posts with this category"},
+			{Name: "keyword", Short: "k", Usage: "Only posts whose title or description contains this"},
+		},
+		Examples: []string{
+			"gator site build ./public",
+			"gator site build ./public --title \"Team reading list\" --templates ./site-templates",
+		},
+	}, commands.HandlerSite)
+
+	commandsStruct.RegisterLoggedIn("tui", commands.CommandInfo{
+		Description: "Interactive terminal reader",
+	}, commands.HandlerTUI)
+
+	commandsStruct.RegisterWithRole("unfollow", commands.CommandInfo{
+		Description: "Unfollow a followed feed",
+		Args:        []commands.Arg{{Name: "url", Complete: commands.CompleteFollowedFeedURLs}},
+		Examples:    []string{"gator unfollow https://blog.boot.dev/index.xml"},
+	}, commands.RoleMember, commands.HandlerUnfollow)
+
+	commandsStruct.Register("users", commands.CommandInfo{
+		Description: "List users, marking the current user",
+		Flags:       commands.OutputFlags,
+	}, commands.HandlerUsers)
+
+	commandsStruct.RegisterLoggedIn("webhook", commands.CommandInfo{
+		Description: "Add, list or remove webhooks that receive new posts, or show their delivery log",
+		Args: []commands.Arg{
+			{Name: "action", Values: []string{"add", "list", "rm", "log"}},
+			{Name: "target", Optional: true},
+		},
+		Flags: append([]commands.Flag{
+			{Name: "feed", Usage: "Only deliver posts from this feed, by URL or name", Complete: commands.CompleteFollowedFeedURLs},
+			{Name: "keyword", Short: "k", Usage: "Only deliver posts whose title or description contains this"},
+			{Name: "secret", Usage: "Signing secret to use instead of a generated one"},
+			{Name: "limit", Short: "n", Kind: commands.IntOption, Default: "20", Usage: "Number of deliveries \"log\" shows"},
+		}, commands.OutputFlags...),
+		Examples: []string{
+			"gator webhook add https://hooks.slack.com/services/T000/B000/XXXX --keyword kubernetes",
+			"gator webhook add https://example.com/hook --feed https://blog.boot.dev/index.xml",
+			"gator webhook list",
+			"gator webhook log 1a2b3c4d",
+			"gator webhook rm 1a2b3c4d",
+		},
+	}, commands.HandlerWebhook)
+
+	args, err := moveGlobalFlags(os.Args)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
-	args := os.Args
 	if len(args) < 2 {
-		fmt.Println(fmt.Errorf("error: provide at least 2 arguments"))
+		commandsStruct.PrintUsage(st.Out)
 		os.Exit(1)
 	}
 
@@ -82,3 +415,37 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// moveGlobalFlags lets the output flags be given before the command name,
+// e.g. "gator --output json users", by moving them after it where the
+// command's flag set parses them.
+func moveGlobalFlags(args []string) ([]string, error) {
+	if len(args) < 2 {
+		return args, nil
+	}
+
+	global := []string{}
+	i := 1
+	for ; i < len(args); i++ {
+		name, _, hasValue := strings.Cut(args[i], "=")
+		if name != "--output" && name != "-o" && name != "--format" {
+			break
+		}
+
+		global = append(global, args[i])
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("error: %s expects a value", name)
+			}
+			i++
+			global = append(global, args[i])
+		}
+	}
+
+	if i >= len(args) {
+		return args[:1], nil
+	}
+
+	moved := append([]string{args[0], args[i]}, global...)
+	return append(moved, args[i+1:]...), nil
+}
@@ -0,0 +1,67 @@
+-- name: CreateAlertRule :one
+INSERT INTO alert_rules (id, user_id, pattern, is_regex, feed_id, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: GetAlertRulesForUser :many
+SELECT alert_rules.*,
+feeds.name AS feed_name,
+(SELECT COUNT(*) FROM alerts WHERE alerts.rule_id = alert_rules.id) AS matches
+FROM alert_rules
+LEFT JOIN feeds
+ON alert_rules.feed_id = feeds.id
+WHERE alert_rules.user_id = $1
+ORDER BY alert_rules.created_at;
+
+-- name: GetAlertRulesForFeed :many
+SELECT alert_rules.*,
+users.name AS user_name
+FROM alert_rules
+INNER JOIN users
+ON alert_rules.user_id = users.id
+WHERE alert_rules.feed_id = sqlc.arg(feed_id)
+OR (alert_rules.feed_id IS NULL AND EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.user_id = alert_rules.user_id AND feed_follows.feed_id = sqlc.arg(feed_id)
+));
+
+-- name: DeleteAlertRule :exec
+DELETE FROM alert_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreateAlert :execrows
+INSERT INTO alerts (id, rule_id, post_id, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (rule_id, post_id) DO NOTHING;
+
+-- name: GetAlertsForUser :many
+SELECT alerts.*,
+alert_rules.pattern,
+alert_rules.is_regex,
+posts.title AS post_title,
+posts.url AS post_url,
+posts.published_at AS post_published_at,
+feeds.name AS feed_name
+FROM alerts
+INNER JOIN alert_rules
+ON alerts.rule_id = alert_rules.id
+INNER JOIN posts
+ON alerts.post_id = posts.id
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE alert_rules.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(since)::timestamp IS NULL OR alerts.created_at >= sqlc.narg(since))
+ORDER BY alerts.created_at DESC
+LIMIT sqlc.arg(page_limit);
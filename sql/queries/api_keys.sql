@@ -0,0 +1,44 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (id, user_id, name, prefix, key_hash, fever_key_hash, created_at, scope)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+RETURNING *;
+
+-- name: GetAPIKeysForUser :many
+SELECT * FROM api_keys
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetUserByAPIKey :one
+SELECT users.* FROM api_keys
+INNER JOIN users
+ON api_keys.user_id = users.id
+WHERE api_keys.key_hash = $1 AND api_keys.scope = $2;
+
+-- name: GetUserByFeverKey :one
+SELECT users.* FROM api_keys
+INNER JOIN users
+ON api_keys.user_id = users.id
+WHERE api_keys.fever_key_hash = $1;
+
+-- name: TouchFeverKey :exec
+UPDATE api_keys
+SET last_used_at = $2
+WHERE fever_key_hash = $1;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = $2
+WHERE key_hash = $1;
+
+-- name: DeleteAPIKey :exec
+DELETE FROM api_keys
+WHERE id = $1 AND user_id = $2;
@@ -0,0 +1,12 @@
+-- name: GetDigestLastSent :one
+SELECT last_sent_at FROM digests
+WHERE user_id = $1;
+
+-- name: SetDigestLastSent :exec
+INSERT INTO digests (user_id, last_sent_at)
+VALUES (
+    $1,
+    $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET last_sent_at = EXCLUDED.last_sent_at;
@@ -0,0 +1,41 @@
+-- name: CreateFeedFetch :exec
+INSERT INTO feed_fetches (id, feed_id, fetched_at, duration_ms, item_count, error)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+);
+
+-- name: DeleteOldFeedFetches :exec
+DELETE FROM feed_fetches
+WHERE feed_id = $1
+AND id NOT IN (
+    SELECT id FROM feed_fetches
+    WHERE feed_id = $1
+    ORDER BY fetched_at DESC
+    LIMIT $2
+);
+
+-- name: GetFeedFetchStats :one
+SELECT COUNT(*) AS fetches,
+COUNT(error) AS failures,
+COALESCE(AVG(duration_ms), 0)::float8 AS avg_duration_ms
+FROM (
+    SELECT duration_ms, error FROM feed_fetches
+    WHERE feed_id = $1
+    ORDER BY fetched_at DESC
+    LIMIT $2
+) AS recent;
+
+-- name: GetLastFeedFetchError :one
+SELECT fetched_at, error FROM feed_fetches
+WHERE feed_id = $1 AND error IS NOT NULL
+ORDER BY fetched_at DESC
+LIMIT 1;
+
+-- name: DeleteFeedFetchesForFeed :exec
+DELETE FROM feed_fetches
+WHERE feed_id = $1;
@@ -20,14 +20,30 @@ ON inserted_feed_follow.user_id = users.id;
 -- name: GetFeedFollowsForUser :many
 SELECT feed_follows.*,
 feeds.name AS feed_name,
+feeds.url AS feed_url,
 users.name AS user_name
 FROM feed_follows
 INNER JOIN feeds
 ON feed_follows.feed_id = feeds.id
 INNER JOIN users
 ON feed_follows.user_id = users.id
-WHERE feed_follows.user_id = $1;
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.name;
 
 -- name: UnfollowFeed :exec
 DELETE FROM feed_follows 
-WHERE user_id = $1 and feed_id = $2;
\ No newline at end of file
+WHERE user_id = $1 and feed_id = $2;
+
+-- name: CountFeedFollowsForFeed :one
+SELECT COUNT(*) FROM feed_follows
+WHERE feed_id = $1;
+
+-- name: CountFeedFollows :one
+SELECT COUNT(*) FROM feed_follows;
+
+-- name: CountFeedFollowsAffectedByUser :one
+SELECT COUNT(*) FROM feed_follows
+WHERE user_id = $1 OR feed_id IN (
+    SELECT id FROM feeds
+    WHERE user_id = $1
+);
@@ -34,4 +34,47 @@ WHERE id = $1;
 -- name: GetNextFeedToFetch :one
 SELECT * FROM feeds
 ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: GetFeedsByName :many
+SELECT * FROM feeds
+WHERE name = $1
+ORDER BY created_at;
+
+-- name: RenameFeed :one
+UPDATE feeds
+SET name = $2, updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: SetFeedURL :one
+UPDATE feeds
+SET url = $2, updated_at = $3, last_fetched_at = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds
+WHERE id = $1;
+
+-- name: CountFeeds :one
+SELECT COUNT(*) FROM feeds;
+
+-- name: CountFeedsForUser :one
+SELECT COUNT(*) FROM feeds
+WHERE user_id = $1;
+
+-- name: GetFeedByID :one
+SELECT * FROM feeds
+WHERE id = $1;
+
+-- name: GetFeedBySeqID :one
+SELECT * FROM feeds
+WHERE seq_id = $1;
+
+-- name: GetFollowedFeeds :many
+SELECT feeds.* FROM feeds
+INNER JOIN feed_follows
+ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.name;
@@ -0,0 +1,20 @@
+-- name: CreateFilter :one
+INSERT INTO filters (id, user_id, field, pattern, is_regex, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: GetFiltersForUser :many
+SELECT * FROM filters
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: DeleteFilter :exec
+DELETE FROM filters
+WHERE id = $1 AND user_id = $2;
@@ -0,0 +1,40 @@
+-- name: MarkPostRead :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $3,
+    $3
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = EXCLUDED.read_at, updated_at = EXCLUDED.updated_at;
+
+-- name: MarkPostUnread :exec
+UPDATE post_states
+SET read_at = NULL, updated_at = $3
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: SetPostStarred :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, starred)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $3,
+    $4
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET starred = EXCLUDED.starred, updated_at = EXCLUDED.updated_at;
+
+-- name: MarkPostsReadBefore :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read_at)
+SELECT sqlc.arg(user_id), posts.id, sqlc.arg(read_at), sqlc.arg(read_at), sqlc.arg(read_at)
+FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+WHERE (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+AND posts.created_at <= sqlc.arg(before)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = EXCLUDED.read_at, updated_at = EXCLUDED.updated_at
+WHERE post_states.read_at IS NULL;
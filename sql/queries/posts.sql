@@ -1,5 +1,5 @@
 -- name: CreatePost :one
-INSERT INTO posts (ID, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (ID, created_at, updated_at, title, url, description, published_at, feed_id, author, categories, fetched_at)
 VALUES (
     $1,
     $2,
@@ -8,15 +8,221 @@ VALUES (
     $4,
     $5,
     $2,
-    $6
+    $6,
+    $7,
+    $8,
+    $9
 )
+ON CONFLICT (url) DO NOTHING
 RETURNING *;
 
--- name: GetPostsForUser :many
+-- name: GetPendingPostsForFeed :many
 SELECT * FROM posts
-WHERE feed_id IN (
-    SELECT feed_id FROM feeds
+WHERE feed_id = $1 AND processed_at IS NULL
+ORDER BY published_at, seq_id;
+
+-- name: MarkPostsProcessed :exec
+UPDATE posts
+SET processed_at = sqlc.arg(processed_at)
+WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: GetPostsForUser :many
+SELECT posts.*,
+feeds.name AS feed_name
+FROM posts
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE posts.feed_id IN (
+    SELECT feed_id FROM feed_follows
     WHERE user_id = $1
 )
-ORDER BY updated_at
-LIMIT $2;
\ No newline at end of file
+ORDER BY posts.published_at DESC, posts.seq_id
+LIMIT $2;
+
+-- name: GetPostsForFeedWithState :many
+SELECT posts.*,
+post_states.read_at,
+COALESCE(post_states.starred, FALSE) AS starred
+FROM posts
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = $1
+WHERE posts.feed_id = $2
+ORDER BY posts.published_at DESC
+LIMIT $3;
+
+
+-- name: GetPostsByIDPrefix :many
+SELECT posts.* FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+WHERE posts.id::text LIKE sqlc.arg(prefix)::text || '%'
+ORDER BY posts.id
+LIMIT 2;
+
+-- name: CountPostsForFeed :one
+SELECT COUNT(*) FROM posts
+WHERE feed_id = $1;
+
+-- name: DeletePostsForFeed :execrows
+DELETE FROM posts
+WHERE feed_id = $1;
+
+-- name: GetFeedPostStats :one
+SELECT MIN(published_at)::timestamp AS oldest,
+MAX(published_at)::timestamp AS newest,
+COUNT(*) FILTER (WHERE published_at >= sqlc.arg(since)) AS recent
+FROM posts
+WHERE feed_id = sqlc.arg(feed_id);
+
+-- name: CountPosts :one
+SELECT COUNT(*) FROM posts;
+
+-- name: CountPostsForUserFeeds :one
+SELECT COUNT(*) FROM posts
+WHERE feed_id IN (
+    SELECT id FROM feeds
+    WHERE user_id = $1
+);
+
+-- name: DeleteAllPosts :execrows
+DELETE FROM posts;
+
+-- name: GetPostsForUserWithState :many
+SELECT posts.*,
+feeds.name AS feed_name,
+post_states.read_at,
+COALESCE(post_states.starred, FALSE) AS starred
+FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id)
+WHERE (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+AND (NOT sqlc.arg(unread_only)::bool OR post_states.read_at IS NULL)
+AND (NOT sqlc.arg(starred_only)::bool OR COALESCE(post_states.starred, FALSE))
+AND (sqlc.narg(since)::timestamp IS NULL OR posts.published_at >= sqlc.narg(since))
+ORDER BY posts.published_at DESC, posts.id
+LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
+
+-- name: GetPostWithState :one
+SELECT posts.*,
+feeds.name AS feed_name,
+post_states.read_at,
+COALESCE(post_states.starred, FALSE) AS starred
+FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id)
+WHERE posts.id = sqlc.arg(post_id);
+
+-- name: GetPostsForReaderStream :many
+SELECT posts.*,
+feeds.name AS feed_name,
+feeds.url AS feed_url,
+post_states.read_at,
+COALESCE(post_states.starred, FALSE) AS starred
+FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id)
+WHERE (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+AND (NOT sqlc.arg(unread_only)::bool OR post_states.read_at IS NULL)
+AND (NOT sqlc.arg(starred_only)::bool OR COALESCE(post_states.starred, FALSE))
+AND (sqlc.narg(since)::timestamp IS NULL OR posts.published_at >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR posts.published_at < sqlc.narg(until))
+ORDER BY CASE WHEN sqlc.arg(oldest_first)::bool THEN posts.published_at END ASC,
+posts.published_at DESC, posts.seq_id
+LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
+
+-- name: GetFollowedPostBySeqID :one
+SELECT posts.* FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+WHERE posts.seq_id = sqlc.arg(seq_id);
+
+-- name: GetReaderItems :many
+SELECT posts.*,
+feeds.name AS feed_name,
+feeds.url AS feed_url,
+post_states.read_at,
+COALESCE(post_states.starred, FALSE) AS starred
+FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id)
+WHERE posts.seq_id = ANY(sqlc.arg(seq_ids)::bigint[])
+ORDER BY posts.published_at DESC, posts.seq_id;
+
+-- name: GetFeverItems :many
+SELECT posts.*,
+feeds.seq_id AS feed_seq_id,
+post_states.read_at,
+COALESCE(post_states.starred, FALSE) AS starred
+FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id)
+WHERE (sqlc.narg(since_id)::bigint IS NULL OR posts.seq_id > sqlc.narg(since_id))
+AND (sqlc.narg(max_id)::bigint IS NULL OR posts.seq_id < sqlc.narg(max_id))
+AND (sqlc.narg(with_ids)::bigint[] IS NULL OR posts.seq_id = ANY(sqlc.narg(with_ids)::bigint[]))
+ORDER BY CASE WHEN sqlc.narg(max_id)::bigint IS NOT NULL THEN posts.seq_id END DESC,
+posts.seq_id
+LIMIT sqlc.arg(page_limit);
+
+-- name: CountFollowedPosts :one
+SELECT COUNT(*) FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1;
+
+-- name: GetUnreadPostSeqIDs :many
+SELECT posts.seq_id, posts.title, posts.url, posts.author, posts.categories FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1
+LEFT JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = $1
+WHERE post_states.read_at IS NULL
+ORDER BY posts.seq_id;
+
+-- name: GetStarredPostSeqIDs :many
+SELECT posts.seq_id, posts.title, posts.url, posts.author, posts.categories FROM posts
+INNER JOIN post_states
+ON post_states.post_id = posts.id AND post_states.user_id = $1
+WHERE post_states.starred
+ORDER BY posts.seq_id;
+
+-- name: GetTimelinePosts :many
+SELECT posts.*,
+feeds.name AS feed_name,
+feeds.url AS feed_url
+FROM posts
+INNER JOIN feed_follows
+ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE (sqlc.narg(tag)::text IS NULL OR EXISTS (
+    SELECT 1 FROM unnest(posts.categories) AS category
+    WHERE lower(category) = lower(sqlc.narg(tag))
+))
+AND (sqlc.narg(keyword)::text IS NULL
+    OR position(lower(sqlc.narg(keyword)) IN lower(posts.title)) > 0
+    OR position(lower(sqlc.narg(keyword)) IN lower(posts.description)) > 0)
+AND (sqlc.narg(fetched_since)::timestamp IS NULL OR posts.fetched_at >= sqlc.narg(fetched_since))
+AND (sqlc.narg(fetched_until)::timestamp IS NULL OR posts.fetched_at < sqlc.narg(fetched_until))
+ORDER BY posts.published_at DESC, posts.seq_id DESC
+LIMIT sqlc.arg(page_limit);
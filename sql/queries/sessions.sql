@@ -0,0 +1,32 @@
+-- name: CreateSession :exec
+INSERT INTO sessions (token_hash, user_id, created_at, expires_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+);
+
+-- name: GetUserBySession :one
+SELECT users.* FROM sessions
+INNER JOIN users
+ON sessions.user_id = users.id
+WHERE sessions.token_hash = $1 AND sessions.expires_at > $2;
+
+-- name: DeleteSession :exec
+DELETE FROM sessions
+WHERE token_hash = $1;
+
+-- name: DeleteSessionsForUser :exec
+DELETE FROM sessions
+WHERE user_id = $1;
+
+-- name: DeleteStaleSessions :exec
+DELETE FROM sessions
+WHERE (sessions.user_id = sqlc.arg(user_id) AND token_hash NOT IN (
+    SELECT recent.token_hash FROM sessions AS recent
+    WHERE recent.user_id = sqlc.arg(user_id)
+    ORDER BY recent.created_at DESC
+    LIMIT sqlc.arg(keep)
+))
+OR expires_at <= sqlc.arg(now);
@@ -1,10 +1,11 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+INSERT INTO users (id, created_at, updated_at, name, role)
 VALUES (
     $1,
     $2,
     $2,
-    $3
+    $3,
+    $4
 )
 RETURNING *;
 
@@ -13,10 +14,35 @@ SELECT * FROM users
 WHERE name = $1;
 
 -- name: GetUsers :many
-SELECT name FROM users;
+SELECT * FROM users
+ORDER BY name;
 
 -- name: ResetUsers :exec
 DELETE FROM users;
 
 -- name: GetNumRecords :one
-SELECT COUNT(*) FROM users;
\ No newline at end of file
+SELECT COUNT(*) FROM users;
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1;
+
+-- name: RenameUser :one
+UPDATE users
+SET name = $2, updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: SetUserPassword :exec
+UPDATE users
+SET password_hash = $2, updated_at = $3
+WHERE id = $1;
+
+-- name: SetUserRole :one
+UPDATE users
+SET role = $2, updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: CountAdmins :one
+SELECT COUNT(*) FROM users
+WHERE role = 'admin';
@@ -0,0 +1,79 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (id, user_id, url, secret, feed_id, keyword, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+)
+RETURNING *;
+
+-- name: GetWebhooksForUser :many
+SELECT webhooks.*,
+feeds.name AS feed_name
+FROM webhooks
+LEFT JOIN feeds
+ON webhooks.feed_id = feeds.id
+WHERE webhooks.user_id = $1
+ORDER BY webhooks.created_at;
+
+-- name: GetWebhooksForFeed :many
+SELECT webhooks.* FROM webhooks
+INNER JOIN feed_follows
+ON feed_follows.user_id = webhooks.user_id AND feed_follows.feed_id = sqlc.arg(feed_id)
+WHERE webhooks.feed_id IS NULL OR webhooks.feed_id = sqlc.arg(feed_id);
+
+-- name: DeleteWebhook :exec
+DELETE FROM webhooks
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (id, webhook_id, post_id, payload, status, created_at, next_attempt_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    'pending',
+    sqlc.arg(created_at),
+    sqlc.arg(created_at)
+)
+ON CONFLICT (webhook_id, post_id) DO NOTHING;
+
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = sqlc.arg(claimed_until)::timestamp
+FROM webhooks
+WHERE webhook_deliveries.webhook_id = webhooks.id
+AND webhook_deliveries.id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= sqlc.arg(now)::timestamp
+    ORDER BY next_attempt_at
+    LIMIT sqlc.arg(page_limit)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING webhook_deliveries.*,
+webhooks.url AS webhook_url,
+webhooks.secret AS webhook_secret;
+
+-- name: UpdateWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET status = $2, attempts = $3, next_attempt_at = $4, response_status = $5, error = $6, delivered_at = $7
+WHERE id = $1;
+
+-- name: GetWebhookDeliveriesForUser :many
+SELECT webhook_deliveries.*,
+webhooks.url AS webhook_url,
+posts.title AS post_title
+FROM webhook_deliveries
+INNER JOIN webhooks
+ON webhook_deliveries.webhook_id = webhooks.id
+INNER JOIN posts
+ON webhook_deliveries.post_id = posts.id
+WHERE webhooks.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(webhook_id)::uuid IS NULL OR webhook_deliveries.webhook_id = sqlc.narg(webhook_id))
+ORDER BY webhook_deliveries.created_at DESC
+LIMIT sqlc.arg(page_limit);
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE feed_fetches (
+    id UUID PRIMARY KEY,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    fetched_at TIMESTAMP NOT NULL,
+    duration_ms BIGINT NOT NULL,
+    item_count INTEGER NOT NULL,
+    error TEXT
+);
+
+CREATE INDEX feed_fetches_feed_id_fetched_at_idx ON feed_fetches (feed_id, fetched_at);
+
+-- +goose Down
+DROP TABLE feed_fetches;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN seq_id BIGSERIAL NOT NULL UNIQUE;
+
+ALTER TABLE api_keys
+ADD COLUMN fever_key_hash TEXT UNIQUE;
+
+-- +goose Down
+ALTER TABLE api_keys
+DROP COLUMN fever_key_hash;
+
+ALTER TABLE feeds
+DROP COLUMN seq_id;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN author TEXT,
+ADD COLUMN categories TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE posts
+DROP COLUMN categories,
+DROP COLUMN author;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE api_keys
+ADD COLUMN scope TEXT NOT NULL DEFAULT 'full';
+
+-- +goose Down
+ALTER TABLE api_keys
+DROP COLUMN scope;
@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN fetched_at TIMESTAMP;
+
+UPDATE posts
+SET fetched_at = created_at;
+
+ALTER TABLE posts
+ALTER COLUMN fetched_at SET NOT NULL;
+
+CREATE INDEX posts_fetched_at_idx ON posts (fetched_at);
+
+CREATE TABLE digests (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    last_sent_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE digests;
+
+ALTER TABLE posts
+DROP COLUMN fetched_at;
@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN processed_at TIMESTAMP;
+
+UPDATE posts
+SET processed_at = fetched_at;
+
+CREATE INDEX posts_pending_idx ON posts (feed_id) WHERE processed_at IS NULL;
+
+CREATE UNIQUE INDEX webhook_deliveries_webhook_id_post_id_idx ON webhook_deliveries (webhook_id, post_id);
+
+-- +goose Down
+DROP INDEX webhook_deliveries_webhook_id_post_id_idx;
+
+ALTER TABLE posts
+DROP COLUMN processed_at;
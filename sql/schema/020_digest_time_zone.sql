@@ -0,0 +1,9 @@
+-- +goose Up
+-- last_sent_at was stored as agg's local time without a zone, which read
+-- back as UTC; existing values are taken to be in the server's time zone
+ALTER TABLE digests
+ALTER COLUMN last_sent_at TYPE TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE digests
+ALTER COLUMN last_sent_at TYPE TIMESTAMP;